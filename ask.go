@@ -0,0 +1,325 @@
+package gplace
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParsedQuery is a natural-language search mapped to a SearchRequest by
+// ParseQuery.
+type ParsedQuery struct {
+	Request SearchRequest `json:"request"`
+	// Near is the landmark or area the query names, e.g. "Shibuya" in "near
+	// Shibuya". Resolve it (Client.Resolve) to set Request.LocationBias.
+	Near string `json:"near,omitempty"`
+	// Matches lists the phrases that set request fields, in query order.
+	Matches []QueryMatch `json:"matches,omitempty"`
+}
+
+// QueryMatch is a phrase ParseQuery recognized and the field it set.
+type QueryMatch struct {
+	Text  string `json:"text"`
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// queryRule is a fixed phrase that sets request fields.
+type queryRule struct {
+	words []string
+	field string
+	apply func(*SearchRequest) string
+}
+
+// openLateKeyword is kept in the text query: the API has no filter for
+// closing time, but Text Search understands the phrase.
+const openLateKeyword = "open late"
+
+func priceRule(phrase string, levels ...int) queryRule {
+	return queryRule{words: strings.Fields(phrase), field: "filters.price_levels", apply: func(req *SearchRequest) string {
+		queryFilters(req).PriceLevels = levels
+		return joinInts(levels)
+	}}
+}
+
+func ratingRule(phrase string, rating float64) queryRule {
+	return queryRule{words: strings.Fields(phrase), field: "filters.min_rating", apply: func(req *SearchRequest) string {
+		return setMinRating(req, rating)
+	}}
+}
+
+func openNowRule(phrase string) queryRule {
+	return queryRule{words: strings.Fields(phrase), field: "filters.open_now", apply: func(req *SearchRequest) string {
+		open := true
+		queryFilters(req).OpenNow = &open
+		return "true"
+	}}
+}
+
+func openLateRule(phrase string) queryRule {
+	return queryRule{words: strings.Fields(phrase), field: "filters.keyword", apply: func(req *SearchRequest) string {
+		queryFilters(req).Keyword = openLateKeyword
+		return openLateKeyword
+	}}
+}
+
+// hereRule drops phrases that mean "where I am", which cannot be resolved
+// offline.
+func hereRule(phrase string) queryRule {
+	return queryRule{words: strings.Fields(phrase)}
+}
+
+var queryRules = []queryRule{
+	priceRule("cheap", 1), priceRule("inexpensive", 1), priceRule("budget", 1), priceRule("affordable", 1),
+	priceRule("$", 1), priceRule("$$", 2), priceRule("$$$", 3), priceRule("$$$$", 4),
+	priceRule("moderate", 2), priceRule("moderately priced", 2), priceRule("mid-range", 2), priceRule("mid-priced", 2),
+	priceRule("expensive", 3, 4), priceRule("pricey", 3, 4), priceRule("upscale", 3, 4), priceRule("fancy", 3, 4),
+	priceRule("high-end", 3, 4), priceRule("very expensive", 4), priceRule("luxury", 4),
+	openNowRule("open now"), openNowRule("open right now"), openNowRule("currently open"), openNowRule("still open"),
+	openLateRule("open late"), openLateRule("late night"), openLateRule("late-night"), openLateRule("open until late"),
+	ratingRule("highly rated", 4.5), ratingRule("top rated", 4.5), ratingRule("top-rated", 4.5), ratingRule("best", 4.5),
+	ratingRule("well rated", 4), ratingRule("well-rated", 4), ratingRule("good reviews", 4), ratingRule("great reviews", 4.5),
+	hereRule("near me"), hereRule("nearby"), hereRule("around me"), hereRule("around here"), hereRule("close by"),
+}
+
+// locationWords introduce the area of a query; the words after them, other
+// than recognized phrases, are the area.
+var locationWords = [][]string{{"close", "to"}, {"next", "to"}, {"near"}, {"around"}, {"in"}, {"at"}}
+
+// queryFillers are dropped from the start of the text query.
+var queryFillers = map[string]bool{
+	"find": true, "show": true, "me": true, "i": true, "want": true, "need": true, "looking": true,
+	"for": true, "a": true, "an": true, "the": true, "some": true, "any": true, "good": true, "places": true,
+	"place": true, "spots": true, "spot": true, "with": true, "that": true, "is": true, "are": true,
+}
+
+var (
+	ratingNumber = regexp.MustCompile(`^([0-4](?:\.\d)?|5(?:\.0)?)\+?$`)
+	ratingLead   = map[string]bool{"rated": true, "rating": true}
+	ratingFiller = map[string]bool{"above": true, "over": true, "at": true, "least": true, "of": true}
+)
+
+// ParseQuery maps text such as "cheap ramen open late near Shibuya" to a
+// search without calling the API: place types from the catalog, price words
+// to PriceLevels, "open now" to OpenNow, "open late" to a keyword, rating
+// phrases ("highly rated", "4.5+ stars") to MinRating, and the area after
+// "near", "in" or "around" to Near. The remaining words are the text query.
+func ParseQuery(text string) ParsedQuery {
+	var parsed ParsedQuery
+	words := strings.Fields(text)
+	lower := make([]string, len(words))
+	for i, word := range words {
+		words[i] = strings.Trim(word, `,.!?;:"'()`)
+		lower[i] = strings.ToLower(words[i])
+	}
+	used := make([]bool, len(words))
+	type match struct {
+		at int
+		QueryMatch
+	}
+	var matches []match
+
+	for i := 0; i < len(words); i++ {
+		if used[i] {
+			continue
+		}
+		if end, value := matchRating(lower, i, &parsed.Request); end > i {
+			markUsed(used, i, end)
+			matches = append(matches, match{i, QueryMatch{Text: strings.Join(words[i:end], " "), Field: "filters.min_rating", Value: value}})
+			i = end - 1
+			continue
+		}
+		for _, rule := range queryRules {
+			if !wordsAt(lower, i, rule.words) {
+				continue
+			}
+			end := i + len(rule.words)
+			markUsed(used, i, end)
+			if rule.apply != nil {
+				matches = append(matches, match{i, QueryMatch{Text: strings.Join(words[i:end], " "), Field: rule.field, Value: rule.apply(&parsed.Request)}})
+			}
+			i = end - 1
+			break
+		}
+	}
+
+	topicEnd := len(words)
+	for i := 0; i < len(words) && topicEnd == len(words); i++ {
+		if used[i] {
+			continue
+		}
+		for _, lead := range locationWords {
+			if !wordsAt(lower, i, lead) {
+				continue
+			}
+			var near []string
+			for j := i + len(lead); j < len(words); j++ {
+				if !used[j] && words[j] != "" {
+					near = append(near, words[j])
+				}
+				used[j] = true
+			}
+			if len(near) > 0 {
+				markUsed(used, i, i+len(lead))
+				parsed.Near = strings.Join(near, " ")
+				topicEnd = i
+			}
+			break
+		}
+	}
+
+	var topic []string
+	for i := 0; i < topicEnd; i++ {
+		if used[i] || words[i] == "" || (len(topic) == 0 && queryFillers[lower[i]]) {
+			continue
+		}
+		topic = append(topic, words[i])
+	}
+	for i := 0; i < topicEnd; {
+		name, size := matchPlaceType(lower, used, i, topicEnd)
+		if size == 0 {
+			i++
+			continue
+		}
+		if parsed.Request.Filters == nil || !slices.Contains(parsed.Request.Filters.Types, name) {
+			filters := queryFilters(&parsed.Request)
+			filters.Types = append(filters.Types, name)
+			matches = append(matches, match{i, QueryMatch{Text: strings.Join(words[i:i+size], " "), Field: "filters.types", Value: name}})
+		}
+		i += size
+	}
+
+	parsed.Request.Query = strings.Join(topic, " ")
+	if parsed.Request.Query == "" && parsed.Request.Filters != nil && len(parsed.Request.Filters.Types) > 0 {
+		parsed.Request.Query = strings.ReplaceAll(parsed.Request.Filters.Types[0], "_", " ")
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].at < matches[b].at })
+	for _, m := range matches {
+		parsed.Matches = append(parsed.Matches, m.QueryMatch)
+	}
+	return parsed
+}
+
+// matchRating recognizes "4.5 stars", "at least 4 stars" and "rated 4 or
+// higher" starting at i, returning the end of the phrase.
+func matchRating(lower []string, i int, req *SearchRequest) (int, string) {
+	j := i
+	lead := ratingLead[lower[j]]
+	if lead {
+		j++
+	}
+	for j < len(lower) && ratingFiller[lower[j]] {
+		j++
+	}
+	if j >= len(lower) || !ratingNumber.MatchString(lower[j]) {
+		return i, ""
+	}
+	rating, _ := strconv.ParseFloat(strings.TrimSuffix(lower[j], "+"), 64)
+	j++
+	if j < len(lower) && (lower[j] == "stars" || lower[j] == "star") {
+		j++
+	} else if !lead {
+		return i, ""
+	}
+	for _, tail := range [][]string{{"or", "higher"}, {"or", "more"}, {"or", "above"}, {"and", "up"}, {"and", "above"}} {
+		if wordsAt(lower, j, tail) {
+			j += len(tail)
+			break
+		}
+	}
+	return j, setMinRating(req, rating)
+}
+
+// placeTypePhrases indexes filterable types by their words ("coffee shop")
+// and, for restaurants and shops, by their first words ("ramen").
+var placeTypePhrases = sync.OnceValue(func() map[string]string {
+	phrases := map[string]string{}
+	for _, placeType := range placeTypeCatalog() {
+		// Areas are what "near" and "in" name, not what to find; "country"
+		// in "country music bar" is no filter.
+		if placeType.Usage == PlaceTypeUsageAny && placeType.Category != "geographical_areas" {
+			phrases[strings.ReplaceAll(placeType.Name, "_", " ")] = placeType.Name
+		}
+	}
+	// Restaurants come first, so "dessert" means dessert_restaurant.
+	for _, suffix := range []string{"_restaurant", "_shop"} {
+		for _, placeType := range placeTypeCatalog() {
+			alias, ok := strings.CutSuffix(placeType.Name, suffix)
+			alias = strings.ReplaceAll(alias, "_", " ")
+			if _, taken := phrases[alias]; ok && placeType.Usage == PlaceTypeUsageAny && !taken {
+				phrases[alias] = placeType.Name
+			}
+		}
+	}
+	phrases["coffee"] = "coffee_shop"
+	phrases["gas"] = "gas_station"
+	return phrases
+})
+
+// matchPlaceType finds the longest type phrase of up to four unused words
+// at i, allowing plurals ("cafes", "noodles").
+func matchPlaceType(lower []string, used []bool, i int, end int) (string, int) {
+	phrases := placeTypePhrases()
+	for size := min(4, end-i); size > 0; size-- {
+		words := make([]string, 0, size)
+		for j := i; j < i+size && !used[j]; j++ {
+			words = append(words, lower[j])
+		}
+		if len(words) < size {
+			continue
+		}
+		last := words[size-1]
+		for _, form := range []string{last, strings.TrimSuffix(last, "s"), strings.TrimSuffix(last, "es")} {
+			words[size-1] = form
+			if name, ok := phrases[strings.Join(words, " ")]; ok {
+				return name, size
+			}
+		}
+	}
+	return "", 0
+}
+
+func wordsAt(lower []string, i int, phrase []string) bool {
+	if i+len(phrase) > len(lower) {
+		return false
+	}
+	for j, word := range phrase {
+		if lower[i+j] != word {
+			return false
+		}
+	}
+	return true
+}
+
+func markUsed(used []bool, from int, to int) {
+	for i := from; i < to; i++ {
+		used[i] = true
+	}
+}
+
+func queryFilters(req *SearchRequest) *Filters {
+	if req.Filters == nil {
+		req.Filters = &Filters{}
+	}
+	return req.Filters
+}
+
+// setMinRating keeps the strictest rating a query asks for.
+func setMinRating(req *SearchRequest, rating float64) string {
+	filters := queryFilters(req)
+	if filters.MinRating == nil || rating > *filters.MinRating {
+		filters.MinRating = &rating
+	}
+	return strconv.FormatFloat(rating, 'f', -1, 64)
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,103 @@
+package gplace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	open := true
+	cases := []struct {
+		text   string
+		query  string
+		near   string
+		want   Filters
+		fields []string
+	}{
+		{
+			text:   "cheap ramen open late near Shibuya",
+			query:  "ramen",
+			near:   "Shibuya",
+			want:   Filters{Keyword: "open late", Types: []string{"ramen_restaurant"}, PriceLevels: []int{1}},
+			fields: []string{"filters.price_levels", "filters.types", "filters.keyword"},
+		},
+		{
+			text:   "Find highly rated sushi restaurants in Ginza, open now",
+			query:  "sushi restaurants",
+			near:   "Ginza",
+			want:   Filters{Types: []string{"sushi_restaurant"}, OpenNow: &open, MinRating: ptrFloat(4.5)},
+			fields: []string{"filters.min_rating", "filters.types", "filters.open_now"},
+		},
+		{
+			text:   "at least 4 stars thai food close to Union Square, San Francisco",
+			query:  "thai food",
+			near:   "Union Square San Francisco",
+			want:   Filters{Types: []string{"thai_restaurant"}, MinRating: ptrFloat(4)},
+			fields: []string{"filters.min_rating", "filters.types"},
+		},
+		{
+			text:   "upscale italian rated 4.2 or higher",
+			query:  "italian",
+			want:   Filters{Types: []string{"italian_restaurant"}, PriceLevels: []int{3, 4}, MinRating: ptrFloat(4.2)},
+			fields: []string{"filters.price_levels", "filters.types", "filters.min_rating"},
+		},
+		{
+			text:   "coffee shops near me",
+			query:  "coffee shops",
+			want:   Filters{Types: []string{"coffee_shop"}},
+			fields: []string{"filters.types"},
+		},
+		{
+			text:   "bar with country music in Nashville",
+			query:  "bar with country music",
+			near:   "Nashville",
+			want:   Filters{Types: []string{"bar"}},
+			fields: []string{"filters.types"},
+		},
+		{
+			text:  "vintage vinyl records in Shimokitazawa",
+			query: "vintage vinyl records",
+			near:  "Shimokitazawa",
+		},
+	}
+	for _, tc := range cases {
+		parsed := ParseQuery(tc.text)
+		if parsed.Request.Query != tc.query || parsed.Near != tc.near {
+			t.Fatalf("%q: got query %q near %q", tc.text, parsed.Request.Query, parsed.Near)
+		}
+		var got Filters
+		if parsed.Request.Filters != nil {
+			got = *parsed.Request.Filters
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%q: got filters %+v, want %+v", tc.text, got, tc.want)
+		}
+		var fields []string
+		for _, match := range parsed.Matches {
+			fields = append(fields, match.Field)
+		}
+		if !reflect.DeepEqual(fields, tc.fields) {
+			t.Fatalf("%q: got matches %+v", tc.text, parsed.Matches)
+		}
+		if parsed.Request.Filters != nil {
+			if err := validatePlaceTypes("filters.types", parsed.Request.Filters.Types); err != nil {
+				t.Fatalf("%q: parsed an unusable type: %v", tc.text, err)
+			}
+		}
+	}
+}
+
+func TestParseQueryTypeOnly(t *testing.T) {
+	parsed := ParseQuery("open now near Tokyo Tower")
+	if parsed.Request.Query != "" || parsed.Near != "Tokyo Tower" || parsed.Request.Filters.OpenNow == nil {
+		t.Fatalf("unexpected parse %+v", parsed)
+	}
+	parsed = ParseQuery("cheap noodles")
+	if parsed.Request.Query != "noodles" || parsed.Request.Filters.Types[0] != "noodle_shop" {
+		t.Fatalf("expected plural type match, got %+v", parsed.Request)
+	}
+}
+
+func ptrFloat(value float64) *float64 {
+	return &value
+}
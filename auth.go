@@ -0,0 +1,23 @@
+package gplace
+
+import (
+	"context"
+	"net/http"
+)
+
+// keyCheckFieldMask requests place IDs only, which Text Search bills under
+// its Essentials (IDs Only) SKU, the cheapest Places request.
+const keyCheckFieldMask = "places.id"
+
+// CheckAPIKey verifies the client's API key with a one-result, IDs-only text
+// search. It returns ErrMissingAPIKey without a key and an *APIError when
+// Google rejects the key.
+func (c *Client) CheckAPIKey(ctx context.Context) error {
+	endpoint, err := c.buildURL("/places:searchText", nil)
+	if err != nil {
+		return err
+	}
+	body := map[string]any{"textQuery": "coffee", "pageSize": 1}
+	_, err = c.doRequest(ctx, http.MethodPost, endpoint, body, keyCheckFieldMask)
+	return err
+}
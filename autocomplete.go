@@ -92,7 +92,13 @@ type structuredFormatPayload struct {
 }
 
 type autocompleteTextPayload struct {
-	Text string `json:"text,omitempty"`
+	Text    string                     `json:"text,omitempty"`
+	Matches []autocompleteMatchPayload `json:"matches,omitempty"`
+}
+
+type autocompleteMatchPayload struct {
+	StartOffset int `json:"startOffset,omitempty"`
+	EndOffset   int `json:"endOffset,omitempty"`
 }
 
 func mapAutocompleteSuggestion(payload autocompleteSuggestionPayload) (AutocompleteSuggestion, bool) {
@@ -100,24 +106,30 @@ func mapAutocompleteSuggestion(payload autocompleteSuggestionPayload) (Autocompl
 		prediction := payload.PlacePrediction
 		structured := prediction.StructuredFormat
 		return AutocompleteSuggestion{
-			Kind:           "place",
-			PlaceID:        prediction.PlaceID,
-			Place:          prediction.Place,
-			Text:           autocompleteText(prediction.Text),
-			MainText:       autocompleteText(structuredText(structured, true)),
-			SecondaryText:  autocompleteText(structuredText(structured, false)),
-			Types:          prediction.Types,
-			DistanceMeters: prediction.DistanceMeters,
+			Kind:                 "place",
+			PlaceID:              prediction.PlaceID,
+			Place:                prediction.Place,
+			Text:                 autocompleteText(prediction.Text),
+			TextMatches:          autocompleteMatches(prediction.Text),
+			MainText:             autocompleteText(structuredText(structured, true)),
+			MainTextMatches:      autocompleteMatches(structuredText(structured, true)),
+			SecondaryText:        autocompleteText(structuredText(structured, false)),
+			SecondaryTextMatches: autocompleteMatches(structuredText(structured, false)),
+			Types:                prediction.Types,
+			DistanceMeters:       prediction.DistanceMeters,
 		}, true
 	}
 	if payload.QueryPrediction != nil {
 		prediction := payload.QueryPrediction
 		structured := prediction.StructuredFormat
 		return AutocompleteSuggestion{
-			Kind:          "query",
-			Text:          autocompleteText(prediction.Text),
-			MainText:      autocompleteText(structuredText(structured, true)),
-			SecondaryText: autocompleteText(structuredText(structured, false)),
+			Kind:                 "query",
+			Text:                 autocompleteText(prediction.Text),
+			TextMatches:          autocompleteMatches(prediction.Text),
+			MainText:             autocompleteText(structuredText(structured, true)),
+			MainTextMatches:      autocompleteMatches(structuredText(structured, true)),
+			SecondaryText:        autocompleteText(structuredText(structured, false)),
+			SecondaryTextMatches: autocompleteMatches(structuredText(structured, false)),
 		}, true
 	}
 	return AutocompleteSuggestion{}, false
@@ -140,6 +152,24 @@ func autocompleteText(payload *autocompleteTextPayload) string {
 	return payload.Text
 }
 
+func autocompleteMatches(payload *autocompleteTextPayload) []TextMatch {
+	if payload == nil || len(payload.Matches) == 0 {
+		return nil
+	}
+	matches := make([]TextMatch, 0, len(payload.Matches))
+	for _, match := range payload.Matches {
+		// Proto3 JSON omits zero offsets; drop empty or inverted ranges.
+		if match.EndOffset <= match.StartOffset {
+			continue
+		}
+		matches = append(matches, TextMatch{StartOffset: match.StartOffset, EndOffset: match.EndOffset})
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches
+}
+
 func applyAutocompleteDefaults(req AutocompleteRequest) AutocompleteRequest {
 	if req.Limit == 0 {
 		req.Limit = defaultAutocompleteLimit
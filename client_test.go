@@ -174,9 +174,9 @@ func TestAutocompleteSuccess(t *testing.T) {
     {
       "placePrediction": {
         "placeId": "place-1",
-        "text": {"text": "Coffee Bar"},
+        "text": {"text": "Coffee Bar", "matches": [{"endOffset": 3}]},
         "structuredFormat": {
-          "mainText": {"text": "Coffee"},
+          "mainText": {"text": "Coffee", "matches": [{"endOffset": 3}]},
           "secondaryText": {"text": "Seattle"}
         },
         "types": ["cafe"]
@@ -214,6 +214,16 @@ func TestAutocompleteSuccess(t *testing.T) {
 	if response.Suggestions[0].Kind != "place" || response.Suggestions[0].PlaceID != "place-1" {
 		t.Fatalf("unexpected place suggestion: %#v", response.Suggestions[0])
 	}
+	mainMatches := response.Suggestions[0].MainTextMatches
+	if len(mainMatches) != 1 || mainMatches[0] != (TextMatch{StartOffset: 0, EndOffset: 3}) {
+		t.Fatalf("unexpected main text matches: %#v", mainMatches)
+	}
+	if len(response.Suggestions[0].TextMatches) != 1 {
+		t.Fatalf("unexpected text matches: %#v", response.Suggestions[0].TextMatches)
+	}
+	if response.Suggestions[0].SecondaryTextMatches != nil {
+		t.Fatalf("unexpected secondary text matches: %#v", response.Suggestions[0].SecondaryTextMatches)
+	}
 	if response.Suggestions[1].Kind != "query" || response.Suggestions[1].Text != "coffee beans" {
 		t.Fatalf("unexpected query suggestion: %#v", response.Suggestions[1])
 	}
@@ -380,6 +390,25 @@ func TestDetailsSuccess(t *testing.T) {
 	}
 }
 
+func TestDetailsSessionToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sessionToken") != "session" {
+			t.Fatalf("unexpected sessionToken: %s", r.URL.Query().Get("sessionToken"))
+		}
+		_, _ = w.Write([]byte(`{"id": "place-123"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL})
+	place, err := client.DetailsWithOptions(context.Background(), DetailsRequest{PlaceID: "place-123", SessionToken: "session"})
+	if err != nil {
+		t.Fatalf("details error: %v", err)
+	}
+	if place.PlaceID != "place-123" {
+		t.Fatalf("unexpected id: %s", place.PlaceID)
+	}
+}
+
 func TestDetailsWithReviews(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.Contains(r.Header.Get("X-Goog-FieldMask"), "reviews") {
@@ -492,6 +521,36 @@ func TestMissingAPIKey(t *testing.T) {
 	}
 }
 
+func TestCheckAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Goog-FieldMask") != keyCheckFieldMask {
+			t.Fatalf("unexpected field mask: %s", r.Header.Get("X-Goog-FieldMask"))
+		}
+		if r.Header.Get("X-Goog-Api-Key") != "good-key" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"API key not valid."}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"places":[{"id":"abc"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "good-key", BaseURL: server.URL})
+	if err := client.CheckAPIKey(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client = NewClient(Options{APIKey: "bad-key", BaseURL: server.URL})
+	var apiErr *APIError
+	if err := client.CheckAPIKey(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected api error, got %v", err)
+	}
+
+	if err := NewClient(Options{}).CheckAPIKey(context.Background()); !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatalf("expected missing api key error, got %v", err)
+	}
+}
+
 func TestValidationErrors(t *testing.T) {
 	client := NewClient(Options{APIKey: "test-key", BaseURL: "http://example.com"})
 
@@ -521,6 +580,11 @@ func TestValidationErrors(t *testing.T) {
 		t.Fatalf("expected location error")
 	}
 
+	_, err = client.Search(context.Background(), SearchRequest{Query: "coffee", AlongRoute: &SearchAlongRoute{}})
+	if err == nil {
+		t.Fatalf("expected along route polyline error")
+	}
+
 	_, err = client.Resolve(context.Background(), LocationResolveRequest{LocationText: ""})
 	if err == nil {
 		t.Fatalf("expected resolve error")
@@ -572,6 +636,23 @@ func TestBuildSearchBodyOmitsEmptyPriceLevels(t *testing.T) {
 	}
 }
 
+func TestSearchFieldMaskForRequest(t *testing.T) {
+	if mask := searchFieldMaskForRequest(SearchRequest{}); mask != searchFieldMask {
+		t.Fatalf("unexpected default mask: %s", mask)
+	}
+	along := SearchRequest{AlongRoute: &SearchAlongRoute{EncodedPolyline: "abc"}}
+	if mask := searchFieldMaskForRequest(along); mask != searchFieldMask {
+		t.Fatalf("routing summaries need an origin: %s", mask)
+	}
+	along.AlongRoute.Origin = &LatLng{Lat: 1, Lng: 2}
+	if mask := searchFieldMaskForRequest(along); !strings.HasSuffix(mask, ","+searchRoutingSummaryMask) {
+		t.Fatalf("expected routing summaries in mask: %s", mask)
+	}
+	if durationSeconds("754.6s") != 755 || durationSeconds("bogus") != 0 {
+		t.Fatalf("unexpected duration parsing")
+	}
+}
+
 func TestMappingHelpers(t *testing.T) {
 	if mapLatLng(nil) != nil {
 		t.Fatalf("expected nil location")
@@ -0,0 +1,200 @@
+package gplace
+
+import "github.com/qztseng/gplace/geo"
+
+const (
+	defaultDedupeNameThreshold = 0.8
+	defaultDedupeMaxDistanceM  = 150
+)
+
+// DedupeOptions sets how alike two records must be to count as one place.
+type DedupeOptions struct {
+	// NameThreshold is the minimum name similarity, from 0 to 1; nil uses
+	// 0.8.
+	NameThreshold *float64 `json:"name_threshold,omitempty"`
+	// MaxDistanceM is the farthest apart two records of one place can be;
+	// nil uses 150, and 0 merges records by place ID only. Branches of a
+	// chain further apart stay separate.
+	MaxDistanceM *float64 `json:"max_distance_m,omitempty"`
+}
+
+// DedupedPlace is the canonical record for a cluster of duplicates.
+type DedupedPlace struct {
+	PlaceSummary
+	// PlaceIDs lists every place ID merged into the record, canonical first.
+	PlaceIDs []string `json:"place_ids"`
+	// Sources are the indexes of the merged input records, ascending, so
+	// callers can map them back to where each record came from.
+	Sources []int `json:"sources"`
+}
+
+// Dedupe clusters place records that describe the same place, e.g. results
+// merged from search, nearby and route. Records with the same place ID are
+// always merged; records with different IDs are merged when their names are
+// similar and their locations close, which catches listings re-created after
+// a move. Records without a location only merge by place ID.
+//
+// Each cluster becomes one record: the member with the most ratings, with
+// empty fields filled from the other members and types combined. Records are
+// returned in order of their first appearance.
+func Dedupe(places []PlaceSummary, opts DedupeOptions) ([]DedupedPlace, error) {
+	opts = applyDedupeDefaults(opts)
+	if err := validateDedupeOptions(opts); err != nil {
+		return nil, err
+	}
+
+	clusters := newUnionFind(len(places))
+	firstByID := make(map[string]int, len(places))
+	for i, place := range places {
+		if place.PlaceID == "" {
+			continue
+		}
+		if first, ok := firstByID[place.PlaceID]; ok {
+			clusters.union(first, i)
+		} else {
+			firstByID[place.PlaceID] = i
+		}
+	}
+	// Pairwise comparison is fine for the few hundred records a merged result
+	// set usually has.
+	for i := range places {
+		if places[i].Location == nil || *opts.MaxDistanceM == 0 {
+			continue
+		}
+		for j := i + 1; j < len(places); j++ {
+			if places[j].Location == nil || clusters.find(i) == clusters.find(j) {
+				continue
+			}
+			if geo.Distance(*places[i].Location, *places[j].Location) > *opts.MaxDistanceM {
+				continue
+			}
+			if nameSimilarity(places[i].Name, places[j].Name) >= *opts.NameThreshold {
+				clusters.union(i, j)
+			}
+		}
+	}
+
+	members := make(map[int][]int, len(places))
+	roots := make([]int, 0, len(places))
+	for i := range places {
+		root := clusters.find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+	deduped := make([]DedupedPlace, 0, len(roots))
+	for _, root := range roots {
+		deduped = append(deduped, mergePlaces(places, members[root]))
+	}
+	return deduped, nil
+}
+
+// mergePlaces builds the canonical record for the records at indexes.
+func mergePlaces(places []PlaceSummary, indexes []int) DedupedPlace {
+	canonical := indexes[0]
+	for _, index := range indexes[1:] {
+		if ratingCount(places[index]) > ratingCount(places[canonical]) {
+			canonical = index
+		}
+	}
+
+	merged := DedupedPlace{PlaceSummary: places[canonical], Sources: indexes}
+	merged.Types = append([]string(nil), merged.Types...)
+	seenIDs := map[string]bool{}
+	seenTypes := map[string]bool{}
+	for _, placeType := range merged.Types {
+		seenTypes[placeType] = true
+	}
+	for _, index := range append([]int{canonical}, indexes...) {
+		place := places[index]
+		if place.PlaceID != "" && !seenIDs[place.PlaceID] {
+			seenIDs[place.PlaceID] = true
+			merged.PlaceIDs = append(merged.PlaceIDs, place.PlaceID)
+		}
+		if merged.Name == "" {
+			merged.Name = place.Name
+		}
+		if merged.Address == "" {
+			merged.Address = place.Address
+		}
+		if merged.Location == nil {
+			merged.Location = place.Location
+		}
+		// Rating and count describe the same listing, so they move together.
+		if merged.Rating == nil && merged.UserRatingCount == nil {
+			merged.Rating = place.Rating
+			merged.UserRatingCount = place.UserRatingCount
+		}
+		if merged.PriceLevel == nil {
+			merged.PriceLevel = place.PriceLevel
+		}
+		if merged.OpenNow == nil {
+			merged.OpenNow = place.OpenNow
+		}
+		for _, placeType := range place.Types {
+			if !seenTypes[placeType] {
+				seenTypes[placeType] = true
+				merged.Types = append(merged.Types, placeType)
+			}
+		}
+	}
+	return merged
+}
+
+func ratingCount(place PlaceSummary) int {
+	if place.UserRatingCount == nil {
+		return -1
+	}
+	return *place.UserRatingCount
+}
+
+func applyDedupeDefaults(opts DedupeOptions) DedupeOptions {
+	if opts.NameThreshold == nil {
+		threshold := defaultDedupeNameThreshold
+		opts.NameThreshold = &threshold
+	}
+	if opts.MaxDistanceM == nil {
+		distance := float64(defaultDedupeMaxDistanceM)
+		opts.MaxDistanceM = &distance
+	}
+	return opts
+}
+
+func validateDedupeOptions(opts DedupeOptions) error {
+	if *opts.NameThreshold < 0 || *opts.NameThreshold > 1 {
+		return ValidationError{Field: "name_threshold", Message: "must be 0-1"}
+	}
+	if *opts.MaxDistanceM < 0 {
+		return ValidationError{Field: "max_distance_m", Message: "must be >= 0"}
+	}
+	return nil
+}
+
+// unionFind groups record indexes into clusters.
+type unionFind []int
+
+func newUnionFind(size int) unionFind {
+	parents := make(unionFind, size)
+	for i := range parents {
+		parents[i] = i
+	}
+	return parents
+}
+
+func (u unionFind) find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+// union keeps the lower index as root so clusters keep input order.
+func (u unionFind) union(a, b int) {
+	rootA, rootB := u.find(a), u.find(b)
+	if rootA > rootB {
+		rootA, rootB = rootB, rootA
+	}
+	u[rootB] = rootA
+}
@@ -0,0 +1,84 @@
+package gplace
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	few, many := 12, 340
+	rating := 4.5
+	price := 2
+	places := []PlaceSummary{
+		{PlaceID: "old", Name: "Blue Bottle Coffee", Location: &LatLng{Lat: 47.6, Lng: -122.3}, UserRatingCount: &few, Types: []string{"cafe"}},
+		{PlaceID: "chain", Name: "Blue Bottle Coffee", Location: &LatLng{Lat: 47.7, Lng: -122.3}},
+		{PlaceID: "new", Name: "Blue Bottle", Address: "1 Main St", Location: &LatLng{Lat: 47.6005, Lng: -122.3}, Rating: &rating, UserRatingCount: &many, Types: []string{"coffee_shop", "cafe"}},
+		{PlaceID: "next", Name: "Starbucks", Location: &LatLng{Lat: 47.6, Lng: -122.3}},
+		{PlaceID: "old", Name: "Blue Bottle Coffee", PriceLevel: &price},
+		{PlaceID: "nowhere", Name: "Blue Bottle Coffee"},
+	}
+
+	deduped, err := Dedupe(places, DedupeOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deduped) != 4 {
+		t.Fatalf("expected 4 places, got %d: %#v", len(deduped), deduped)
+	}
+
+	merged := deduped[0]
+	if merged.PlaceID != "new" || merged.Address != "1 Main St" || merged.Rating == nil || *merged.UserRatingCount != many {
+		t.Fatalf("expected most-rated record as canonical: %#v", merged)
+	}
+	if merged.PriceLevel == nil || *merged.PriceLevel != price {
+		t.Fatalf("expected price level filled from a duplicate: %#v", merged)
+	}
+	if !reflect.DeepEqual(merged.PlaceIDs, []string{"new", "old"}) {
+		t.Fatalf("unexpected place IDs: %v", merged.PlaceIDs)
+	}
+	if !reflect.DeepEqual(merged.Sources, []int{0, 2, 4}) {
+		t.Fatalf("unexpected sources: %v", merged.Sources)
+	}
+	if !reflect.DeepEqual(merged.Types, []string{"coffee_shop", "cafe"}) {
+		t.Fatalf("unexpected types: %v", merged.Types)
+	}
+	if places[2].Types[0] != "coffee_shop" || len(places[2].Types) != 2 {
+		t.Fatalf("input types modified: %v", places[2].Types)
+	}
+
+	for i, id := range []string{"chain", "next", "nowhere"} {
+		if deduped[i+1].PlaceID != id || len(deduped[i+1].Sources) != 1 {
+			t.Fatalf("expected %s to stay separate: %#v", id, deduped[i+1])
+		}
+	}
+}
+
+func TestDedupeOptions(t *testing.T) {
+	places := []PlaceSummary{
+		{PlaceID: "a", Name: "Cafe Uno", Location: &LatLng{Lat: 47.6, Lng: -122.3}},
+		{PlaceID: "b", Name: "Cafe Uno", Location: &LatLng{Lat: 47.603, Lng: -122.3}},
+	}
+	if deduped, _ := Dedupe(places, DedupeOptions{}); len(deduped) != 2 {
+		t.Fatalf("expected places 330m apart to stay separate: %#v", deduped)
+	}
+	if deduped, _ := Dedupe(places, DedupeOptions{MaxDistanceM: ptrFloat(500)}); len(deduped) != 1 {
+		t.Fatalf("expected places within 500m to merge: %#v", deduped)
+	}
+
+	// Zero is a setting, not "use the default".
+	same := []PlaceSummary{places[0], {PlaceID: "c", Name: "Cafe Uno", Location: places[0].Location}, places[0]}
+	if deduped, _ := Dedupe(same, DedupeOptions{MaxDistanceM: ptrFloat(0)}); len(deduped) != 2 {
+		t.Fatalf("expected a zero distance to merge by place ID only: %#v", deduped)
+	}
+	different := []PlaceSummary{places[0], {PlaceID: "d", Name: "Bakery Dos", Location: places[0].Location}}
+	if deduped, _ := Dedupe(different, DedupeOptions{NameThreshold: ptrFloat(0)}); len(deduped) != 1 {
+		t.Fatalf("expected a zero name threshold to merge any names: %#v", deduped)
+	}
+
+	_, err := Dedupe(places, DedupeOptions{NameThreshold: ptrFloat(1.5)})
+	var validation ValidationError
+	if !errors.As(err, &validation) || validation.Field != "name_threshold" {
+		t.Fatalf("expected name_threshold validation error, got %v", err)
+	}
+}
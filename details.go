@@ -28,6 +28,7 @@ func (c *Client) DetailsWithOptions(ctx context.Context, req DetailsRequest) (Pl
 	endpoint, err := c.buildURL("/places/"+placeID, map[string]string{
 		"languageCode": strings.TrimSpace(req.Language),
 		"regionCode":   strings.TrimSpace(req.Region),
+		"sessionToken": strings.TrimSpace(req.SessionToken),
 	})
 	if err != nil {
 		return PlaceDetails{}, err
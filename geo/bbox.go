@@ -0,0 +1,60 @@
+package geo
+
+import "math"
+
+// BBox is a latitude/longitude bounding box. Boxes crossing the antimeridian
+// are not supported.
+type BBox struct {
+	SouthWest LatLng `json:"south_west"`
+	NorthEast LatLng `json:"north_east"`
+}
+
+// Bounds returns the smallest box containing points; the zero box for none.
+func Bounds(points []LatLng) BBox {
+	if len(points) == 0 {
+		return BBox{}
+	}
+	box := BBox{SouthWest: points[0], NorthEast: points[0]}
+	for _, point := range points[1:] {
+		box.SouthWest.Lat = math.Min(box.SouthWest.Lat, point.Lat)
+		box.SouthWest.Lng = math.Min(box.SouthWest.Lng, point.Lng)
+		box.NorthEast.Lat = math.Max(box.NorthEast.Lat, point.Lat)
+		box.NorthEast.Lng = math.Max(box.NorthEast.Lng, point.Lng)
+	}
+	return box
+}
+
+// Contains reports whether point lies inside the box, edges included.
+func (b BBox) Contains(point LatLng) bool {
+	return point.Lat >= b.SouthWest.Lat && point.Lat <= b.NorthEast.Lat &&
+		point.Lng >= b.SouthWest.Lng && point.Lng <= b.NorthEast.Lng
+}
+
+// Center returns the midpoint of the box in latitude and longitude.
+func (b BBox) Center() LatLng {
+	return LatLng{
+		Lat: (b.SouthWest.Lat + b.NorthEast.Lat) / 2,
+		Lng: (b.SouthWest.Lng + b.NorthEast.Lng) / 2,
+	}
+}
+
+// Expand grows the box by meters on every side, clamped to valid coordinates.
+func (b BBox) Expand(meters float64) BBox {
+	dlat := degrees(meters / EarthRadiusMeters)
+	south := math.Max(b.SouthWest.Lat-dlat, -90)
+	north := math.Min(b.NorthEast.Lat+dlat, 90)
+
+	// Longitude degrees shrink toward the poles; widen by the worst case.
+	cos := math.Cos(radians(math.Max(math.Abs(south), math.Abs(north))))
+	if cos < 1e-9 {
+		return BBox{
+			SouthWest: LatLng{Lat: south, Lng: -180},
+			NorthEast: LatLng{Lat: north, Lng: 180},
+		}
+	}
+	dlng := dlat / cos
+	return BBox{
+		SouthWest: LatLng{Lat: south, Lng: math.Max(b.SouthWest.Lng-dlng, -180)},
+		NorthEast: LatLng{Lat: north, Lng: math.Min(b.NorthEast.Lng+dlng, 180)},
+	}
+}
@@ -0,0 +1,87 @@
+// Package geo provides polyline encoding and spherical geometry helpers used
+// by route search.
+//
+// Distances are in meters on a spherical Earth; angles are in degrees.
+package geo
+
+import "math"
+
+// EarthRadiusMeters is the mean Earth radius used for all distances.
+const EarthRadiusMeters = 6371000.0
+
+// LatLng holds geographic coordinates.
+type LatLng struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Distance returns the great-circle (haversine) distance between a and b.
+func Distance(a, b LatLng) float64 {
+	lat1 := radians(a.Lat)
+	lat2 := radians(b.Lat)
+	dlat := radians(b.Lat - a.Lat)
+	dlng := radians(b.Lng - a.Lng)
+
+	sinDLat := math.Sin(dlat / 2)
+	sinDLng := math.Sin(dlng / 2)
+	value := sinDLat*sinDLat + math.Cos(lat1)*math.Cos(lat2)*sinDLng*sinDLng
+	return 2 * EarthRadiusMeters * math.Asin(math.Sqrt(math.Min(value, 1)))
+}
+
+// Bearing returns the initial bearing from a to b, clockwise from north in
+// [0, 360).
+func Bearing(a, b LatLng) float64 {
+	lat1 := radians(a.Lat)
+	lat2 := radians(b.Lat)
+	dlng := radians(b.Lng - a.Lng)
+
+	y := math.Sin(dlng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlng)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// Destination returns the point reached by travelling distance meters from
+// start on the given initial bearing.
+func Destination(start LatLng, bearing float64, distance float64) LatLng {
+	angular := distance / EarthRadiusMeters
+	theta := radians(bearing)
+	lat1 := radians(start.Lat)
+	lng1 := radians(start.Lng)
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(
+		math.Sin(theta)*math.Sin(angular)*math.Cos(lat1),
+		math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2),
+	)
+	return LatLng{Lat: degrees(lat2), Lng: normalizeLng(degrees(lng2))}
+}
+
+// Length returns the total length of a polyline.
+func Length(points []LatLng) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += Distance(points[i-1], points[i])
+	}
+	return total
+}
+
+// CumulativeDistances returns the distance from the first point to each point.
+func CumulativeDistances(points []LatLng) []float64 {
+	distances := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		distances[i] = distances[i-1] + Distance(points[i-1], points[i])
+	}
+	return distances
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+func normalizeLng(lng float64) float64 {
+	return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+}
@@ -0,0 +1,147 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	// One degree of longitude on the equator.
+	distance := Distance(LatLng{Lat: 0, Lng: 0}, LatLng{Lat: 0, Lng: 1})
+	if math.Abs(distance-111195) > 1 {
+		t.Fatalf("unexpected distance: %f", distance)
+	}
+	if Distance(LatLng{Lat: 10, Lng: 10}, LatLng{Lat: 10, Lng: 10}) != 0 {
+		t.Fatalf("expected zero distance")
+	}
+}
+
+func TestBearing(t *testing.T) {
+	origin := LatLng{Lat: 0, Lng: 0}
+	cases := []struct {
+		to   LatLng
+		want float64
+	}{
+		{LatLng{Lat: 1, Lng: 0}, 0},
+		{LatLng{Lat: 0, Lng: 1}, 90},
+		{LatLng{Lat: -1, Lng: 0}, 180},
+		{LatLng{Lat: 0, Lng: -1}, 270},
+	}
+	for _, tc := range cases {
+		if got := Bearing(origin, tc.to); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("bearing to %#v: expected %f, got %f", tc.to, tc.want, got)
+		}
+	}
+}
+
+func TestDestination(t *testing.T) {
+	start := LatLng{Lat: 47.6, Lng: -122.3}
+	end := Destination(start, 45, 10000)
+	if math.Abs(Distance(start, end)-10000) > 0.01 {
+		t.Fatalf("unexpected distance: %f", Distance(start, end))
+	}
+	if math.Abs(Bearing(start, end)-45) > 0.1 {
+		t.Fatalf("unexpected bearing: %f", Bearing(start, end))
+	}
+
+	wrapped := Destination(LatLng{Lat: 0, Lng: 179.9}, 90, 50000)
+	if wrapped.Lng > -179 || wrapped.Lng < -180 {
+		t.Fatalf("expected longitude to wrap: %#v", wrapped)
+	}
+}
+
+func TestLength(t *testing.T) {
+	if Length([]LatLng{{Lat: 1, Lng: 1}}) != 0 {
+		t.Fatalf("expected zero length")
+	}
+	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 0, Lng: 2}}
+	cumulative := CumulativeDistances(points)
+	if cumulative[0] != 0 || math.Abs(cumulative[2]-Length(points)) > 1e-6 {
+		t.Fatalf("unexpected cumulative distances: %v", cumulative)
+	}
+}
+
+func TestLinePointAt(t *testing.T) {
+	line := NewLine([]LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}})
+	if got := line.PointAt(-1); got != line.Points[0] {
+		t.Fatalf("expected first point, got %#v", got)
+	}
+	if got := line.PointAt(line.Length() + 1); got != line.Points[1] {
+		t.Fatalf("expected last point, got %#v", got)
+	}
+	if got := line.PointAt(line.Length() / 2); math.Abs(got.Lng-1) > 1e-9 {
+		t.Fatalf("expected midpoint, got %#v", got)
+	}
+	if got := PointAlong(nil, 10); got != (LatLng{}) {
+		t.Fatalf("expected empty point, got %#v", got)
+	}
+}
+
+func TestClosestPoint(t *testing.T) {
+	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 0.5}, {Lat: 0, Lng: 1}}
+	projection := ClosestPoint(points, LatLng{Lat: 0.01, Lng: 0.75})
+	if projection.Segment != 1 || math.Abs(projection.Point.Lng-0.75) > 1e-9 {
+		t.Fatalf("unexpected projection: %#v", projection)
+	}
+	if math.Abs(projection.Along-Distance(points[0], LatLng{Lng: 0.75})) > 1 {
+		t.Fatalf("unexpected along: %f", projection.Along)
+	}
+	if math.Abs(projection.Offset-1112) > 2 {
+		t.Fatalf("unexpected offset: %f", projection.Offset)
+	}
+
+	// Points beyond the ends project onto the endpoints.
+	before := ClosestPoint(points, LatLng{Lat: 0, Lng: -0.1})
+	if before.Point != points[0] || before.Along != 0 {
+		t.Fatalf("unexpected projection before start: %#v", before)
+	}
+	if (ClosestPoint(nil, LatLng{}) != Projection{}) {
+		t.Fatalf("expected empty projection")
+	}
+}
+
+func TestWithinBuffer(t *testing.T) {
+	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}
+	if !WithinBuffer(points, LatLng{Lat: 0.005, Lng: 0.5}, 1000) {
+		t.Fatalf("expected point inside buffer")
+	}
+	if WithinBuffer(points, LatLng{Lat: 0.02, Lng: 0.5}, 1000) {
+		t.Fatalf("expected point outside buffer")
+	}
+	if WithinBuffer(nil, LatLng{}, 1000) {
+		t.Fatalf("expected empty line to contain nothing")
+	}
+}
+
+func TestBounds(t *testing.T) {
+	box := Bounds([]LatLng{{Lat: 1, Lng: 5}, {Lat: -2, Lng: 3}, {Lat: 0, Lng: 4}})
+	if box.SouthWest != (LatLng{Lat: -2, Lng: 3}) || box.NorthEast != (LatLng{Lat: 1, Lng: 5}) {
+		t.Fatalf("unexpected bounds: %#v", box)
+	}
+	if !box.Contains(LatLng{Lat: 0, Lng: 3}) || box.Contains(LatLng{Lat: 2, Lng: 4}) {
+		t.Fatalf("unexpected contains result")
+	}
+	if center := box.Center(); center != (LatLng{Lat: -0.5, Lng: 4}) {
+		t.Fatalf("unexpected center: %#v", center)
+	}
+	if Bounds(nil) != (BBox{}) {
+		t.Fatalf("expected zero box")
+	}
+}
+
+func TestBufferBounds(t *testing.T) {
+	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}
+	box := BufferBounds(points, 1000)
+	if math.Abs(box.NorthEast.Lat-0.008993) > 1e-5 || math.Abs(box.SouthWest.Lng+0.008993) > 1e-5 {
+		t.Fatalf("unexpected buffer bounds: %#v", box)
+	}
+	// Every point within the buffer lies inside the bounds.
+	if edge := Destination(LatLng{Lat: 0, Lng: 0.5}, 0, 999); !box.Contains(edge) {
+		t.Fatalf("expected %#v inside %#v", edge, box)
+	}
+
+	polar := Bounds([]LatLng{{Lat: 89.99, Lng: 0}}).Expand(5000)
+	if polar.NorthEast.Lat != 90 || polar.SouthWest.Lng != -180 || polar.NorthEast.Lng != 180 {
+		t.Fatalf("expected polar box to span all longitudes: %#v", polar)
+	}
+}
@@ -0,0 +1,131 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// Line is a polyline with precomputed cumulative distances, for repeated
+// point-along and closest-point queries.
+type Line struct {
+	Points []LatLng
+	// Cumulative holds the distance along the line to each point.
+	Cumulative []float64
+}
+
+// Projection is the closest point on a line to a query point.
+type Projection struct {
+	Point LatLng `json:"point"`
+	// Along is the distance along the line to Point.
+	Along float64 `json:"along_m"`
+	// Offset is the distance from the query point to Point.
+	Offset float64 `json:"offset_m"`
+	// Segment is the index of the segment's first vertex.
+	Segment int `json:"segment"`
+}
+
+// NewLine builds a Line from points.
+func NewLine(points []LatLng) *Line {
+	return &Line{Points: points, Cumulative: CumulativeDistances(points)}
+}
+
+// Length returns the line length.
+func (l *Line) Length() float64 {
+	if len(l.Cumulative) == 0 {
+		return 0
+	}
+	return l.Cumulative[len(l.Cumulative)-1]
+}
+
+// PointAt returns the point distance meters along the line, clamped to its
+// ends. Segments are interpolated linearly in latitude and longitude.
+func (l *Line) PointAt(distance float64) LatLng {
+	if len(l.Points) == 0 {
+		return LatLng{}
+	}
+	if distance <= 0 {
+		return l.Points[0]
+	}
+	if distance >= l.Length() {
+		return l.Points[len(l.Points)-1]
+	}
+	index := sort.Search(len(l.Cumulative), func(i int) bool {
+		return l.Cumulative[i] >= distance
+	})
+	if index == 0 {
+		return l.Points[0]
+	}
+	prev := l.Points[index-1]
+	next := l.Points[index]
+	segment := l.Cumulative[index] - l.Cumulative[index-1]
+	if segment <= 0 {
+		return next
+	}
+	return interpolate(prev, next, (distance-l.Cumulative[index-1])/segment)
+}
+
+// Closest projects point onto the line. Each segment is treated as straight in
+// an equirectangular projection, which is accurate for route-scale segments.
+func (l *Line) Closest(point LatLng) Projection {
+	if len(l.Points) == 0 {
+		return Projection{}
+	}
+	best := Projection{Point: l.Points[0], Offset: Distance(l.Points[0], point)}
+	for i := 1; i < len(l.Points); i++ {
+		start, end := l.Points[i-1], l.Points[i]
+		scale := math.Cos(radians(start.Lat))
+		dx := (end.Lng - start.Lng) * scale
+		dy := end.Lat - start.Lat
+		px := (point.Lng - start.Lng) * scale
+		py := point.Lat - start.Lat
+
+		fraction := 0.0
+		if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+			fraction = math.Max(0, math.Min(1, (px*dx+py*dy)/lengthSquared))
+		}
+		projected := interpolate(start, end, fraction)
+		if distance := Distance(projected, point); distance < best.Offset {
+			best = Projection{
+				Point:   projected,
+				Along:   l.Cumulative[i-1] + (l.Cumulative[i]-l.Cumulative[i-1])*fraction,
+				Offset:  distance,
+				Segment: i - 1,
+			}
+		}
+	}
+	return best
+}
+
+// Within reports whether point lies within radius meters of the line.
+func (l *Line) Within(point LatLng, radius float64) bool {
+	return len(l.Points) > 0 && l.Closest(point).Offset <= radius
+}
+
+// PointAlong returns the point distance meters along points.
+func PointAlong(points []LatLng, distance float64) LatLng {
+	return NewLine(points).PointAt(distance)
+}
+
+// ClosestPoint projects point onto the polyline given by points.
+func ClosestPoint(points []LatLng, point LatLng) Projection {
+	return NewLine(points).Closest(point)
+}
+
+// WithinBuffer reports whether point lies inside the buffer of radius meters
+// around the polyline given by points.
+func WithinBuffer(points []LatLng, point LatLng, radius float64) bool {
+	return NewLine(points).Within(point, radius)
+}
+
+// BufferBounds returns a bounding box covering the buffer of radius meters
+// around points.
+func BufferBounds(points []LatLng, radius float64) BBox {
+	return Bounds(points).Expand(radius)
+}
+
+func interpolate(a, b LatLng, fraction float64) LatLng {
+	return LatLng{
+		Lat: a.Lat + (b.Lat-a.Lat)*fraction,
+		Lng: a.Lng + (b.Lng-a.Lng)*fraction,
+	}
+}
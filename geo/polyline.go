@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Polyline precisions: Google Maps uses 5 decimal places; OSRM, Valhalla and
+// other routers commonly use 6.
+const (
+	Precision5 = 5
+	Precision6 = 6
+)
+
+// maxPolylineShift bounds a single encoded value to seven 5-bit chunks.
+const maxPolylineShift = 30
+
+var (
+	// ErrInvalidPolyline is returned for truncated or malformed polylines.
+	ErrInvalidPolyline = errors.New("geo: invalid polyline")
+	// ErrInvalidPrecision is returned for precisions outside 1-10.
+	ErrInvalidPrecision = errors.New("geo: precision must be 1-10")
+)
+
+// Decode decodes an encoded polyline with the given precision (decimal
+// places). An empty string decodes to no points.
+func Decode(encoded string, precision int) ([]LatLng, error) {
+	factor, err := precisionFactor(precision)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]LatLng, 0, len(encoded)/4)
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		deltaLat, next, err := decodeValue(encoded, i)
+		if err != nil {
+			return nil, err
+		}
+		deltaLng, next, err := decodeValue(encoded, next)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		lat += deltaLat
+		lng += deltaLng
+		points = append(points, LatLng{
+			Lat: float64(lat) / factor,
+			Lng: float64(lng) / factor,
+		})
+	}
+	return points, nil
+}
+
+// Encode encodes points as a polyline with the given precision (decimal
+// places). Coordinates are rounded to that precision.
+func Encode(points []LatLng, precision int) (string, error) {
+	factor, err := precisionFactor(precision)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	var prevLat, prevLng int64
+	for _, point := range points {
+		lat := int64(math.Round(point.Lat * factor))
+		lng := int64(math.Round(point.Lng * factor))
+		encodeValue(&out, lat-prevLat)
+		encodeValue(&out, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return out.String(), nil
+}
+
+func decodeValue(encoded string, i int) (int64, int, error) {
+	var result int64
+	var shift uint
+	for {
+		if i >= len(encoded) || shift > maxPolylineShift {
+			return 0, i, ErrInvalidPolyline
+		}
+		b := int64(encoded[i]) - 63
+		i++
+		if b < 0 || b > 0x3f {
+			return 0, i, ErrInvalidPolyline
+		}
+		result |= (b & 0x1f) << shift
+		shift += 5
+		if b < 0x20 {
+			break
+		}
+	}
+	return (result >> 1) ^ -(result & 1), i, nil
+}
+
+func encodeValue(out *strings.Builder, value int64) {
+	// Zigzag encoding keeps small negative deltas short.
+	v := uint64(value << 1)
+	if value < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		out.WriteByte(byte((v&0x1f)|0x20) + 63)
+		v >>= 5
+	}
+	out.WriteByte(byte(v) + 63)
+}
+
+func precisionFactor(precision int) (float64, error) {
+	if precision < 1 || precision > 10 {
+		return 0, ErrInvalidPrecision
+	}
+	return math.Pow10(precision), nil
+}
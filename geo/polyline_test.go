@@ -0,0 +1,149 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const googleExample = "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+
+var googleExamplePoints = []LatLng{
+	{Lat: 38.5, Lng: -120.2},
+	{Lat: 40.7, Lng: -120.95},
+	{Lat: 43.252, Lng: -126.453},
+}
+
+func TestDecode(t *testing.T) {
+	points, err := Decode(googleExample, Precision5)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(points) != len(googleExamplePoints) {
+		t.Fatalf("expected %d points, got %d", len(googleExamplePoints), len(points))
+	}
+	for i, want := range googleExamplePoints {
+		if points[i] != want {
+			t.Fatalf("point %d: expected %#v, got %#v", i, want, points[i])
+		}
+	}
+
+	// The same string read at precision 6 scales every coordinate down by 10.
+	points6, err := Decode(googleExample, Precision6)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if math.Abs(points6[0].Lat-3.85) > 1e-9 || math.Abs(points6[0].Lng+12.02) > 1e-9 {
+		t.Fatalf("unexpected precision 6 point: %#v", points6[0])
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	points, err := Decode("", Precision5)
+	if err != nil || len(points) != 0 {
+		t.Fatalf("expected no points, got %#v %v", points, err)
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	for _, encoded := range []string{"abc", "_p~iF~ps|U_", "_p~iF ps|U", "~~~~~~~~~~~~~~~~?"} {
+		if _, err := Decode(encoded, Precision5); !errors.Is(err, ErrInvalidPolyline) {
+			t.Fatalf("%q: expected invalid polyline error, got %v", encoded, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	encoded, err := Encode(googleExamplePoints, Precision5)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if encoded != googleExample {
+		t.Fatalf("expected %s, got %s", googleExample, encoded)
+	}
+
+	precise := []LatLng{{Lat: 47.608013, Lng: -122.335167}, {Lat: 45.515232, Lng: -122.678385}}
+	encoded, err = Encode(precise, Precision6)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	decoded, err := Decode(encoded, Precision6)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	for i := range precise {
+		if math.Abs(decoded[i].Lat-precise[i].Lat) > 1e-9 || math.Abs(decoded[i].Lng-precise[i].Lng) > 1e-9 {
+			t.Fatalf("point %d: expected %#v, got %#v", i, precise[i], decoded[i])
+		}
+	}
+}
+
+func TestInvalidPrecision(t *testing.T) {
+	if _, err := Decode(googleExample, 0); !errors.Is(err, ErrInvalidPrecision) {
+		t.Fatalf("expected precision error, got %v", err)
+	}
+	if _, err := Encode(googleExamplePoints, 11); !errors.Is(err, ErrInvalidPrecision) {
+		t.Fatalf("expected precision error, got %v", err)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(googleExample, Precision5)
+	f.Add("", Precision6)
+	f.Add("??", Precision5)
+	f.Add("_p~iF~ps|U_", Precision6)
+	f.Fuzz(func(t *testing.T, encoded string, precision int) {
+		points, err := Decode(encoded, precision)
+		if err != nil {
+			return
+		}
+		// Whatever decodes must survive an encode/decode round trip.
+		reencoded, err := Encode(points, precision)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		again, err := Decode(reencoded, precision)
+		if err != nil {
+			t.Fatalf("decode of %q error: %v", reencoded, err)
+		}
+		if len(again) != len(points) {
+			t.Fatalf("expected %d points, got %d", len(points), len(again))
+		}
+		for i := range points {
+			if again[i] != points[i] {
+				t.Fatalf("point %d: expected %#v, got %#v", i, points[i], again[i])
+			}
+		}
+	})
+}
+
+func FuzzEncodeRoundTrip(f *testing.F) {
+	f.Add(38.5, -120.2, 43.252, -126.453)
+	f.Add(-90.0, 180.0, 90.0, -180.0)
+	f.Add(0.0, 0.0, 0.0, 0.0)
+	f.Fuzz(func(t *testing.T, lat1, lng1, lat2, lng2 float64) {
+		points := []LatLng{{Lat: lat1, Lng: lng1}, {Lat: lat2, Lng: lng2}}
+		for _, point := range points {
+			if math.IsNaN(point.Lat) || math.IsNaN(point.Lng) ||
+				math.Abs(point.Lat) > 90 || math.Abs(point.Lng) > 180 {
+				return
+			}
+		}
+		for _, precision := range []int{Precision5, Precision6} {
+			encoded, err := Encode(points, precision)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			decoded, err := Decode(encoded, precision)
+			if err != nil {
+				t.Fatalf("decode of %q error: %v", encoded, err)
+			}
+			tolerance := 0.5/math.Pow10(precision) + 1e-12
+			for i := range points {
+				if math.Abs(decoded[i].Lat-points[i].Lat) > tolerance || math.Abs(decoded[i].Lng-points[i].Lng) > tolerance {
+					t.Fatalf("precision %d point %d: expected %#v, got %#v", precision, i, points[i], decoded[i])
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/qztseng/gplace"
+)
+
+// AskCmd turns a natural-language query into a search and runs it.
+type AskCmd struct {
+	Query    []string `arg:"" name:"query" help:"What to find, e.g. \"cheap ramen open late near Shibuya\"."`
+	Limit    int      `help:"Max results (1-20)." default:"10"`
+	RadiusM  float64  `help:"Location bias radius in meters around the named area." default:"2000"`
+	Language string   `help:"BCP-47 language code (e.g. en, en-US)."`
+	Region   string   `help:"CLDR region code (e.g. US, DE)."`
+	DryRun   bool     `help:"Print the parsed request and matched phrases without calling the API."`
+}
+
+// A dry run only parses the query.
+func (c *AskCmd) offline() bool { return c.DryRun }
+
+// Run executes the ask command.
+func (c *AskCmd) Run(app *App) error {
+	if c.RadiusM <= 0 {
+		return gplace.ValidationError{Field: "radius_m", Message: "must be > 0"}
+	}
+	parsed := gplace.ParseQuery(strings.Join(c.Query, " "))
+	parsed.Request.Limit = c.Limit
+	parsed.Request.Language = c.Language
+	parsed.Request.Region = c.Region
+	if c.DryRun {
+		switch app.format {
+		case formatText:
+			_, err := fmt.Fprintln(app.out, renderParsedQuery(app.color, parsed))
+			return err
+		case formatJSON:
+			return writeJSON(app.out, parsed)
+		default:
+			return unsupportedFormat(app.format, "ask --dry-run")
+		}
+	}
+	if strings.TrimSpace(parsed.Request.Query) == "" {
+		return gplace.ValidationError{Field: "query", Message: "names no place to find; add what you are looking for, e.g. ramen"}
+	}
+
+	ctx := context.Background()
+	request := parsed.Request
+	if parsed.Near != "" {
+		area, err := app.client.Resolve(ctx, gplace.LocationResolveRequest{
+			LocationText: parsed.Near,
+			Limit:        1,
+			Language:     c.Language,
+			Region:       c.Region,
+		})
+		if err != nil {
+			return err
+		}
+		if len(area.Results) > 0 && area.Results[0].Location != nil {
+			resolved := area.Results[0]
+			request.LocationBias = &gplace.LocationBias{Lat: resolved.Location.Lat, Lng: resolved.Location.Lng, RadiusM: c.RadiusM}
+			_, _ = fmt.Fprintf(app.err, "%s %s → %s\n", app.color.Dim("Near:"), parsed.Near, formatTitle(app.color, resolved.Name, resolved.Address))
+		} else {
+			// Let Text Search interpret the area rather than dropping it.
+			request.Query += " near " + parsed.Near
+			_, _ = fmt.Fprintf(app.err, "%s %q not found; searching for %q\n", app.color.Dim("Near:"), parsed.Near, request.Query)
+		}
+	}
+	if payload, err := json.Marshal(request); err == nil {
+		_, _ = fmt.Fprintf(app.err, "%s %s\n", app.color.Dim("Search:"), payload)
+	}
+
+	response, err := app.client.Search(ctx, request)
+	if err != nil {
+		return err
+	}
+	if app.format != formatText {
+		if err := outputPlaces(app, response.Results); err != nil {
+			return err
+		}
+		return outputPageToken(app, response.NextPageToken)
+	}
+	_, err = fmt.Fprintln(app.out, renderSearch(app.color, response))
+	return err
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunAsk(t *testing.T) {
+	var search map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != placesSearchPath {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body["textQuery"] == "Shibuya" {
+			_, _ = w.Write([]byte(`{"places":[{"id":"area","displayName":{"text":"Shibuya"},"location":{"latitude":35.66,"longitude":139.7}}]}`))
+			return
+		}
+		search = body
+		_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Ramen Shop"}}]}`))
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"ask", "cheap ramen open late near Shibuya", "--radius-m", "1500", "--json", "--api-key", "test-key", "--base-url", server.URL}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if search["textQuery"] != "ramen open late" || search["includedType"] != "ramen_restaurant" {
+		t.Fatalf("unexpected search body %v", search)
+	}
+	if levels, _ := search["priceLevels"].([]any); len(levels) != 1 || levels[0] != "PRICE_LEVEL_INEXPENSIVE" {
+		t.Fatalf("unexpected price levels %v", search["priceLevels"])
+	}
+	circle := search["locationBias"].(map[string]any)["circle"].(map[string]any)
+	if circle["radius"] != 1500.0 || circle["center"].(map[string]any)["latitude"] != 35.66 {
+		t.Fatalf("unexpected location bias %v", circle)
+	}
+	if !strings.Contains(stderr.String(), `"query":"ramen"`) || !strings.Contains(stdout.String(), `"place_id": "abc"`) {
+		t.Fatalf("expected the request on stderr and results on stdout, got stdout=%s stderr=%s", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunAskDryRun(t *testing.T) {
+	// A dry run needs no API key.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GPLACE_CONFIG", "")
+	t.Setenv("GPLACE_API_KEY_CMD", "")
+	t.Setenv(apiKeyEnv, "")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"ask", "--dry-run", "highly", "rated", "sushi", "in", "Ginza", "--no-color"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	want := "Query: sushi\nNear: Ginza\nMatches:\n  \"highly rated\" → filters.min_rating = 4.5\n  \"sushi\" → filters.types = sushi_restaurant\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected dry run text %q", stdout.String())
+	}
+
+	stdout.Reset()
+	code = Run([]string{"ask", "--dry-run", "highly", "rated", "sushi", "in", "Ginza", "--json"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	var parsed struct {
+		Request struct {
+			Query   string `json:"query"`
+			Filters struct {
+				Types     []string `json:"types"`
+				MinRating float64  `json:"min_rating"`
+			} `json:"filters"`
+		} `json:"request"`
+		Near string `json:"near"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &parsed); err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	if parsed.Request.Query != "sushi" || parsed.Near != "Ginza" || parsed.Request.Filters.MinRating != 4.5 || parsed.Request.Filters.Types[0] != "sushi_restaurant" {
+		t.Fatalf("unexpected dry run %+v", parsed)
+	}
+
+	stderr.Reset()
+	code = Run([]string{"ask", "--dry-run", "sushi", "--format", "csv"}, &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), "csv is not supported for ask --dry-run") {
+		t.Fatalf("expected exit code 2 for csv, got %d (stderr=%s)", code, stderr.String())
+	}
+
+	stderr.Reset()
+	code = Run([]string{"ask", "open now near Tokyo Tower", "--api-key", "test-key", "--base-url", "http://127.0.0.1:0"}, &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), "no place to find") {
+		t.Fatalf("expected exit code 2 for a query without a topic, got %d (stderr=%s)", code, stderr.String())
+	}
+}
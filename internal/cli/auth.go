@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/qztseng/gplace"
+)
+
+// apiKeyEnv is the environment variable of --api-key.
+const apiKeyEnv = "GOOGLE_PLACES_API_KEY"
+
+// AuthCmd manages the stored API key.
+type AuthCmd struct {
+	Login  AuthLoginCmd  `cmd:"" help:"Store an API key (read from stdin) or a command that prints one."`
+	Status AuthStatusCmd `cmd:"" help:"Show where the API key comes from and check it with an IDs-only search."`
+	Logout AuthLogoutCmd `cmd:"" help:"Delete the stored API key."`
+}
+
+// AuthLoginCmd stores an API key in the credentials file.
+type AuthLoginCmd struct {
+	Command string `help:"Store this shell command instead of a key; its first output line is the key (e.g. 'pass show google/places')."`
+}
+
+// AuthStatusCmd reports and checks the API key in use.
+type AuthStatusCmd struct{}
+
+// AuthLogoutCmd deletes the credentials file.
+type AuthLogoutCmd struct{}
+
+// offlineCommand is implemented by commands that may not call the API. When
+// offline reports true for the parsed flags, no API key is looked up (and no
+// --api-key-cmd run).
+type offlineCommand interface {
+	offline() bool
+}
+
+func (*AuthLoginCmd) offline() bool  { return true }
+func (*AuthLogoutCmd) offline() bool { return true }
+
+// credentials is the content of the credentials file: a key, or a command
+// that prints one.
+type credentials struct {
+	APIKey    string `json:"api_key,omitempty"`
+	APIKeyCmd string `json:"api_key_cmd,omitempty"`
+}
+
+type authStatus struct {
+	Source string `json:"source,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Valid  bool   `json:"valid"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Run executes the auth login command.
+func (c *AuthLoginCmd) Run(app *App) error {
+	path := credentialsPath()
+	if path == "" {
+		return errors.New("gplace: no home directory for the credentials file")
+	}
+
+	stored := credentials{APIKeyCmd: strings.TrimSpace(c.Command)}
+	if stored.APIKeyCmd == "" {
+		key, err := readSecret(app, "Google Places API key: ")
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return gplace.ValidationError{Field: "api_key", Message: "required on stdin"}
+		}
+		stored.APIKey = key
+	}
+
+	payload, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writePrivateFile(path, append(payload, '\n')); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(app.out, "Saved to %s. Run `gplace auth status` to check the key.\n", path)
+	return err
+}
+
+// Run executes the auth status command.
+func (c *AuthStatusCmd) Run(app *App) error {
+	if app.format != formatText && app.format != formatJSON {
+		return unsupportedFormat(app.format, "auth status")
+	}
+	status := authStatus{Source: app.keySource, Key: maskKey(app.apiKey)}
+	err := app.client.CheckAPIKey(context.Background())
+	status.Valid = err == nil
+	if err != nil && !errors.Is(err, gplace.ErrMissingAPIKey) {
+		status.Error = err.Error()
+	}
+
+	if app.format == formatJSON {
+		if writeErr := writeJSON(app.out, status); writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+	if status.Source != "" {
+		_, _ = fmt.Fprintf(app.out, "%s %s (from %s)\n", app.color.Dim("API key:"), status.Key, status.Source)
+	}
+	if err != nil {
+		return err
+	}
+	_, writeErr := fmt.Fprintf(app.out, "%s %s\n", app.color.Dim("Check:"), app.color.Green("ok"))
+	return writeErr
+}
+
+// Run executes the auth logout command.
+func (c *AuthLogoutCmd) Run(app *App) error {
+	path := credentialsPath()
+	if path == "" {
+		return errors.New("gplace: no home directory for the credentials file")
+	}
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, err = fmt.Fprintln(app.out, "No stored API key.")
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("gplace: remove credentials: %w", err)
+	}
+	_, err = fmt.Fprintf(app.out, "Removed %s.\n", path)
+	return err
+}
+
+// resolveAPIKey finds the API key and describes where it came from. The
+// first source that is set wins: --api-key (or its environment variable or
+// config key), --api-key-cmd, then the credentials file.
+func resolveAPIKey(ctx *kong.Context, global GlobalOptions, stderr io.Writer) (string, string, error) {
+	if global.APIKey != "" {
+		return global.APIKey, apiKeySource(ctx), nil
+	}
+	if global.APIKeyCmd != "" {
+		key, err := runAPIKeyCmd(global.APIKeyCmd, stderr)
+		return key, "--api-key-cmd", err
+	}
+
+	path := credentialsPath()
+	if path == "" {
+		return "", "", nil
+	}
+	stored, err := readCredentials(path)
+	if err != nil || (stored.APIKey == "" && stored.APIKeyCmd == "") {
+		return "", "", err
+	}
+	if stored.APIKey != "" {
+		return stored.APIKey, path, nil
+	}
+	key, err := runAPIKeyCmd(stored.APIKeyCmd, stderr)
+	return key, path + " (api_key_cmd)", err
+}
+
+// apiKeySource reports whether --api-key was given on the command line, by
+// the config file or by its environment variable.
+func apiKeySource(ctx *kong.Context) string {
+	for _, trace := range ctx.Path {
+		if trace.Flag != nil && trace.Flag.Name == "api-key" {
+			if trace.Resolved {
+				return "config file"
+			}
+			return "--api-key"
+		}
+	}
+	return apiKeyEnv
+}
+
+// runAPIKeyCmd runs command in the shell and returns the first line it
+// prints, so password managers that print metadata after the secret work.
+// The command reads the terminal for prompts such as a GPG passphrase, never
+// gplace's stdin, which may be piped input for batch, enrich or dedupe.
+func runAPIKeyCmd(command string, stderr io.Writer) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.Command(shell, flag, command)
+	// Without a terminal the command reads an empty stdin.
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer func() { _ = tty.Close() }()
+		cmd.Stdin = tty
+	}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gplace: api key command: %w", err)
+	}
+	key, _, _ := strings.Cut(string(output), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errors.New("gplace: api key command printed no key")
+	}
+	return key, nil
+}
+
+// credentialsPath is $XDG_CONFIG_HOME/gplace/credentials.json.
+func credentialsPath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "credentials.json")
+}
+
+// readCredentials reads the credentials file. A missing file holds no
+// credentials.
+func readCredentials(path string) (credentials, error) {
+	payload, err := readPrivateFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return credentials{}, nil
+	}
+	if err != nil {
+		return credentials{}, err
+	}
+	var stored credentials
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return credentials{}, fmt.Errorf("gplace: parse credentials %s: %w", path, err)
+	}
+	return stored, nil
+}
+
+// readPrivateFile reads a file holding secrets, refusing one that other
+// users can access.
+func readPrivateFile(path string) ([]byte, error) {
+	if err := checkPrivateFile(path); err != nil {
+		return nil, err
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gplace: read %s: %w", path, err)
+	}
+	return payload, nil
+}
+
+// checkPrivateFile refuses a file holding secrets that other users can
+// access.
+func checkPrivateFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("gplace: read %s: %w", path, err)
+	}
+	// Windows does not report Unix permission bits.
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("gplace: %s is accessible by other users; run chmod 600 %s", path, path)
+	}
+	return nil
+}
+
+// writePrivateFile replaces path with payload, readable only by the owner.
+// The temporary file is created 0600, so the key is never briefly exposed.
+func writePrivateFile(path string, payload []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("gplace: create config directory: %w", err)
+	}
+	file, err := os.CreateTemp(dir, ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("gplace: write credentials: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.Write(payload); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("gplace: write credentials: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("gplace: write credentials: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("gplace: write credentials: %w", err)
+	}
+	return nil
+}
+
+// readSecret reads one line from app.in. On a terminal it prompts on stderr
+// and hides the input; piped input is read as is.
+func readSecret(app *App, prompt string) (string, error) {
+	if restore, err := disableEcho(int(app.in.Fd())); err == nil {
+		_, _ = fmt.Fprint(app.err, prompt)
+		defer func() {
+			_ = restore()
+			_, _ = fmt.Fprintln(app.err)
+		}()
+	}
+	line, err := bufio.NewReader(app.in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("gplace: read api key: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// maskKey shows only the ends of key.
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:4] + "…" + key[len(key)-4:]
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func setupAuthTest(t *testing.T) (string, *httptest.Server, *string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("GPLACE_CONFIG", "")
+	t.Setenv("GPLACE_API_KEY_CMD", "")
+	t.Setenv(apiKeyEnv, "")
+
+	var gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Goog-Api-Key")
+		if gotKey != "AIza-test-key-1234" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"API key not valid."}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"places":[]}`))
+	}))
+	t.Cleanup(server.Close)
+	return filepath.Join(dir, "gplace", "credentials.json"), server, &gotKey
+}
+
+func TestAuthLoginStatusLogout(t *testing.T) {
+	path, server, _ := setupAuthTest(t)
+
+	input := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(input, []byte("AIza-test-key-1234\n"), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	in, err := os.Open(input)
+	if err != nil {
+		t.Fatalf("open key: %v", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	app := &App{in: in, out: &stdout, err: &stderr, format: formatText}
+	if err := (&AuthLoginCmd{}).Run(app); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat credentials: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 credentials, got %v", info.Mode().Perm())
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("expected no prompt for piped input, got %q", stderr.String())
+	}
+
+	stdout.Reset()
+	exitCode := Run([]string{"auth", "status", "--base-url", server.URL, "--no-color"}, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if stdout.String() != "API key: AIza…1234 (from "+path+")\nCheck: ok\n" {
+		t.Fatalf("unexpected status: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if exitCode := Run([]string{"auth", "logout"}, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected credentials removed, got %v", err)
+	}
+	if exitCode := Run([]string{"auth", "status", "--base-url", server.URL}, &stdout, &stderr); exitCode != 2 {
+		t.Fatalf("expected missing key exit code 2, got %d", exitCode)
+	}
+}
+
+func TestAuthStatusInvalidKeyJSON(t *testing.T) {
+	_, server, _ := setupAuthTest(t)
+	t.Setenv(apiKeyEnv, "AIza-wrong-key-0000")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := Run([]string{"auth", "status", "--base-url", server.URL, "--json"}, &stdout, &stderr)
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", exitCode)
+	}
+	var status authStatus
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		t.Fatalf("parse status: %v", err)
+	}
+	if status.Source != apiKeyEnv || status.Key != "AIza…0000" || status.Valid || !strings.Contains(status.Error, "400") {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+}
+
+func TestAuthAPIKeyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	path, server, gotKey := setupAuthTest(t)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := Run([]string{"search", "coffee", "--api-key-cmd", "printf 'AIza-test-key-1234\\nuser: me\\n'", "--base-url", server.URL}, &stdout, &stderr)
+	if exitCode != 0 || *gotKey != "AIza-test-key-1234" {
+		t.Fatalf("expected key from command, got %q (exit %d, stderr=%s)", *gotKey, exitCode, stderr.String())
+	}
+
+	// A stored command runs when no other key is set.
+	if exitCode := Run([]string{"auth", "login", "--command", "echo AIza-test-key-1234"}, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("login: exit %d (stderr=%s)", exitCode, stderr.String())
+	}
+	*gotKey = ""
+	if exitCode := Run([]string{"search", "coffee", "--base-url", server.URL}, &stdout, &stderr); exitCode != 0 || *gotKey != "AIza-test-key-1234" {
+		t.Fatalf("expected key from stored command, got %q (exit %d)", *gotKey, exitCode)
+	}
+
+	// Credentials other users can read are refused.
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	stderr.Reset()
+	if exitCode := Run([]string{"search", "coffee", "--base-url", server.URL}, &stdout, &stderr); exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "chmod 600") {
+		t.Fatalf("unexpected error: %s", stderr.String())
+	}
+}
+
+func TestAPIKeyCommandKeepsPipedInput(t *testing.T) {
+	_, server, gotKey := setupAuthTest(t)
+	// Reads stdin only when it is not a terminal, so the test cannot hang.
+	t.Setenv("GPLACE_API_KEY_CMD", "[ -t 0 ] || cat >/dev/null; echo AIza-test-key-1234")
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	cases := []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"batch", "-", "--base-url", server.URL}, `{"op":"search","query":"coffee"}` + "\n", `"type":"result"`},
+		{[]string{"dedupe", "-", "--no-color"}, `[{"place_id":"a","name":"Cafe Uno"}]`, "Places (1 from 1 records)"},
+	}
+	for _, tc := range cases {
+		stdin, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("pipe: %v", err)
+		}
+		if _, err := writer.WriteString(tc.input); err != nil {
+			t.Fatalf("write input: %v", err)
+		}
+		_ = writer.Close()
+
+		// The key command must not inherit the process stdin either.
+		processStdin := os.Stdin
+		os.Stdin = stdin
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		exitCode := run(tc.args, stdin, &stdout, &stderr)
+		os.Stdin = processStdin
+		_ = stdin.Close()
+		if exitCode != 0 || !strings.Contains(stdout.String(), tc.want) {
+			t.Fatalf("%s: expected the piped input to reach the command, got exit %d stdout=%s stderr=%s", tc.args[0], exitCode, stdout.String(), stderr.String())
+		}
+	}
+	if *gotKey != "AIza-test-key-1234" {
+		t.Fatalf("expected key from command, got %q", *gotKey)
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qztseng/gplace"
+)
+
+const (
+	// maxCommandWorkers caps --concurrency for commands that run one request
+	// per input row.
+	maxCommandWorkers = 20
+	// maxBatchLine bounds one JSONL request; route requests with many stops
+	// are the largest.
+	maxBatchLine = 1 << 20
+)
+
+// Values of the "op" field of a batch request line.
+const (
+	batchSearch       = "search"
+	batchNearby       = "nearby"
+	batchAutocomplete = "autocomplete"
+	batchDetails      = "details"
+	batchResolve      = "resolve"
+	batchRoute        = "route"
+)
+
+// BatchCmd executes JSONL requests concurrently.
+type BatchCmd struct {
+	File        string `arg:"" optional:"" name:"file" help:"JSONL requests, one per line (default or - reads stdin)."`
+	Concurrency int    `help:"Requests run in parallel (1-20)." default:"4"`
+	Checkpoint  string `help:"Record finished line indexes here and skip them on the next run." type:"path"`
+}
+
+type batchJob struct {
+	index int
+	line  []byte
+}
+
+// Run executes the batch command.
+func (c *BatchCmd) Run(app *App) error {
+	switch app.format {
+	case formatText, formatJSON, formatNDJSON:
+	default:
+		return unsupportedFormat(app.format, "batch")
+	}
+	if c.Concurrency < 1 || c.Concurrency > maxCommandWorkers {
+		return gplace.ValidationError{Field: "concurrency", Message: fmt.Sprintf("must be 1-%d", maxCommandWorkers)}
+	}
+
+	input := io.Reader(app.in)
+	if c.File != "" && c.File != "-" {
+		file, err := os.Open(c.File)
+		if err != nil {
+			return fmt.Errorf("gplace: open batch file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		input = file
+	}
+
+	done, err := readCheckpoint(c.Checkpoint)
+	if err != nil {
+		return err
+	}
+	var checkpoint io.Writer
+	if c.Checkpoint != "" {
+		file, err := os.OpenFile(c.Checkpoint, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("gplace: open checkpoint: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		checkpoint = file
+	}
+
+	ctx := context.Background()
+	jobs := make(chan batchJob)
+	var mu sync.Mutex
+	var writeErr error
+	failed := 0
+	var wg sync.WaitGroup
+	for range c.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				record := runBatchLine(ctx, app.client, job.line)
+				record.Index = &job.index
+
+				mu.Lock()
+				if record.Type == recordError {
+					failed++
+				}
+				if writeErr == nil {
+					writeErr = writeRecord(app.out, record)
+				}
+				// Only successes are checkpointed, so a resumed run retries failures.
+				if writeErr == nil && checkpoint != nil && record.Type == recordResult {
+					_, writeErr = fmt.Fprintln(checkpoint, job.index)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchLine)
+	total := 0
+	for index := 0; scanner.Scan(); index++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || done[index] {
+			continue
+		}
+		total++
+		jobs <- batchJob{index: index, line: append([]byte(nil), line...)}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("gplace: read batch input: %w", err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("gplace: %d of %d batch requests failed", failed, total)
+	}
+	return nil
+}
+
+// runBatchLine decodes and executes one request line, returning its result
+// or error record.
+func runBatchLine(ctx context.Context, client *gplace.Client, line []byte) ndjsonRecord {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return errorRecord(gplace.ValidationError{Field: "line", Message: "invalid JSON: " + err.Error()})
+	}
+	var op string
+	if raw, ok := fields["op"]; ok {
+		_ = json.Unmarshal(raw, &op)
+	}
+	delete(fields, "op")
+
+	var result any
+	var err error
+	switch op {
+	case batchSearch:
+		var req gplace.SearchRequest
+		if err = decodeBatchRequest(fields, &req); err == nil {
+			result, err = client.Search(ctx, req)
+		}
+	case batchNearby:
+		var req gplace.NearbySearchRequest
+		if err = decodeBatchRequest(fields, &req); err == nil {
+			result, err = client.NearbySearch(ctx, req)
+		}
+	case batchAutocomplete:
+		var req gplace.AutocompleteRequest
+		if err = decodeBatchRequest(fields, &req); err == nil {
+			result, err = client.Autocomplete(ctx, req)
+		}
+	case batchDetails:
+		var req gplace.DetailsRequest
+		if err = decodeBatchRequest(fields, &req); err == nil {
+			result, err = client.DetailsWithOptions(ctx, req)
+		}
+	case batchResolve:
+		var req gplace.LocationResolveRequest
+		if err = decodeBatchRequest(fields, &req); err == nil {
+			result, err = client.Resolve(ctx, req)
+		}
+	case batchRoute:
+		var req gplace.RouteRequest
+		if err = decodeBatchRequest(fields, &req); err == nil {
+			result, err = client.Route(ctx, req)
+		}
+	default:
+		ops := []string{batchSearch, batchNearby, batchAutocomplete, batchDetails, batchResolve, batchRoute}
+		err = gplace.ValidationError{Field: "op", Message: fmt.Sprintf("%q is not one of %s", op, strings.Join(ops, ", "))}
+	}
+
+	if err != nil {
+		record := errorRecord(err)
+		record.Op = op
+		return record
+	}
+	return ndjsonRecord{Type: recordResult, Op: op, Data: result}
+}
+
+// decodeBatchRequest decodes the request fields (without "op") strictly, so
+// misspelled fields are reported instead of ignored.
+func decodeBatchRequest(fields map[string]json.RawMessage, target any) error {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return decodeRequest(bytes.NewReader(payload), target)
+}
+
+// decodeRequest decodes one JSON request object into target, rejecting
+// unknown fields.
+func decodeRequest(reader io.Reader, target any) error {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return gplace.ValidationError{Field: "request", Message: err.Error()}
+	}
+	return nil
+}
+
+// readCheckpoint returns the line indexes recorded by earlier runs; a missing
+// file means nothing is done yet.
+func readCheckpoint(path string) (map[int]bool, error) {
+	done := map[int]bool{}
+	if path == "" {
+		return done, nil
+	}
+	payload, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gplace: read checkpoint: %w", err)
+	}
+	for _, line := range strings.Fields(string(payload)) {
+		index, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("gplace: invalid checkpoint entry %q", line)
+		}
+		done[index] = true
+	}
+	return done, nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == placesSearchPath:
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+		case strings.HasPrefix(r.URL.Path, "/places/"):
+			_, _ = w.Write([]byte(`{"id":"abc","displayName":{"text":"Cafe"},"formattedAddress":"1 Main St"}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	input := strings.Join([]string{
+		`{"op":"search","query":"coffee","limit":1}`,
+		``,
+		`{"op":"details","place_id":"abc"}`,
+		`{"op":"teleport"}`,
+		`{"op":"search","qurey":"typo"}`,
+		`not json`,
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"batch", path,
+		"--concurrency", "3",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1 for failed lines, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "3 of 5 batch requests failed") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+
+	records := readRecords(t, &stdout)
+	sort.Slice(records, func(i, j int) bool { return records[i]["index"].(float64) < records[j]["index"].(float64) })
+	var summary []string
+	for _, record := range records {
+		op, _ := record["op"].(string)
+		summary = append(summary, record["type"].(string)+":"+op)
+	}
+	if strings.Join(summary, ",") != "result:search,result:details,error:teleport,error:search,error:" {
+		t.Fatalf("unexpected records: %v", summary)
+	}
+	if records[1]["index"].(float64) != 2 || records[1]["data"].(map[string]any)["address"] != "1 Main St" {
+		t.Fatalf("unexpected details record: %v", records[1])
+	}
+	if !strings.Contains(records[3]["error"].(string), "qurey") || records[3]["field"] != "request" {
+		t.Fatalf("expected unknown field error: %v", records[3])
+	}
+}
+
+func TestRunBatchCheckpoint(t *testing.T) {
+	var calls atomic.Int32
+	fail := atomic.Bool{}
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["textQuery"] == "flaky" && fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"places":[]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "requests.jsonl")
+	checkpoint := filepath.Join(dir, "checkpoint")
+	lines := `{"op":"search","query":"a"}` + "\n" + `{"op":"search","query":"flaky"}` + "\n" + `{"op":"search","query":"b"}` + "\n"
+	if err := os.WriteFile(input, []byte(lines), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	args := []string{"batch", input, "--checkpoint", checkpoint, "--format", "ndjson", "--api-key", "test-key", "--base-url", server.URL}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if exitCode := Run(args, &stdout, &stderr); exitCode != 1 {
+		t.Fatalf("expected first run to fail, got %d", exitCode)
+	}
+	saved, err := os.ReadFile(checkpoint)
+	if err != nil {
+		t.Fatalf("read checkpoint: %v", err)
+	}
+	done := strings.Fields(string(saved))
+	sort.Strings(done)
+	if strings.Join(done, ",") != "0,2" {
+		t.Fatalf("unexpected checkpoint: %q", saved)
+	}
+
+	fail.Store(false)
+	calls.Store(0)
+	stdout.Reset()
+	if exitCode := Run(args, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("expected resumed run to succeed, got %d (%s)", exitCode, stdout.String())
+	}
+	records := readRecords(t, &stdout)
+	if calls.Load() != 1 || len(records) != 1 || records[0]["index"].(float64) != 1 {
+		t.Fatalf("expected only line 1 to rerun, got %d calls: %s", calls.Load(), stdout.String())
+	}
+}
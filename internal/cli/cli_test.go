@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/qztseng/gplace"
@@ -16,6 +17,7 @@ const (
 	placesSearchPath  = "/places:searchText"
 	placesNearbyPath  = "/places:searchNearby"
 	routesComputePath = "/directions/v2:computeRoutes"
+	routesMatrixPath  = "/distanceMatrix/v2:computeRouteMatrix"
 )
 
 func TestRunSearchJSON(t *testing.T) {
@@ -314,6 +316,310 @@ func TestRunRouteHuman(t *testing.T) {
 	}
 }
 
+func TestRunRouteWithStops(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesComputePath:
+			if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			_, _ = w.Write([]byte("{\"routes\":[{\"polyline\":{\"encodedPolyline\":\"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case placesSearchPath:
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"coffee",
+		"--from-place-id", "place-origin",
+		"--to", "45.5,-122.6",
+		"--via", "Olympia, WA",
+		"--via", "place_id:place-stop",
+		"--avoid", "tolls,ferries",
+		"--departure-time", "2026-05-01T09:00:00-07:00",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--routes-base-url", server.URL,
+		"--json",
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stdout=%s stderr=%s)", exitCode, stdout.String(), stderr.String())
+	}
+	if gotBody["origin"].(map[string]any)["placeId"] != "place-origin" {
+		t.Fatalf("unexpected origin: %#v", gotBody["origin"])
+	}
+	if _, ok := gotBody["destination"].(map[string]any)["location"]; !ok {
+		t.Fatalf("unexpected destination: %#v", gotBody["destination"])
+	}
+	intermediates := gotBody["intermediates"].([]any)
+	if len(intermediates) != 2 || intermediates[0].(map[string]any)["address"] != "Olympia, WA" {
+		t.Fatalf("unexpected intermediates: %#v", intermediates)
+	}
+	modifiers := gotBody["routeModifiers"].(map[string]any)
+	if modifiers["avoidTolls"] != true || modifiers["avoidFerries"] != true {
+		t.Fatalf("unexpected route modifiers: %#v", modifiers)
+	}
+	if gotBody["departureTime"] != "2026-05-01T16:00:00Z" {
+		t.Fatalf("unexpected departure time: %#v", gotBody["departureTime"])
+	}
+}
+
+func TestRunRouteNativeStrategy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesComputePath:
+			_, _ = w.Write([]byte("{\"routes\":[{\"polyline\":{\"encodedPolyline\":\"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"},\"duration\":\"600s\"}]}"))
+		case placesSearchPath:
+			var payload map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			if payload["searchAlongRouteParameters"] == nil {
+				t.Fatalf("expected search along route parameters: %#v", payload)
+			}
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Fuel"}}],"routingSummaries":[{"legs":[{"duration":"400s"},{"duration":"500s"}]}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"gas",
+		"--from", "A",
+		"--to", "B",
+		"--strategy", "native",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--routes-base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stdout=%s stderr=%s)", exitCode, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Fuel") || !strings.Contains(stdout.String(), "+5 min") {
+		t.Fatalf("unexpected stdout: %s", stdout.String())
+	}
+}
+
+func TestRunRouteDetours(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesComputePath:
+			_, _ = w.Write([]byte("{\"routes\":[{\"polyline\":{\"encodedPolyline\":\"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case placesSearchPath:
+			_, _ = w.Write([]byte(`{"places":[{"id":"near","displayName":{"text":"Near Cafe"}},{"id":"far","displayName":{"text":"Far Cafe"}}]}`))
+		case routesMatrixPath:
+			var payload struct {
+				Origins []any `json:"origins"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			if len(payload.Origins) == 1 {
+				_, _ = w.Write([]byte(`[
+  {"originIndex":0,"destinationIndex":0,"duration":"400s","condition":"ROUTE_EXISTS"},
+  {"originIndex":0,"destinationIndex":1,"duration":"900s","condition":"ROUTE_EXISTS"},
+  {"originIndex":0,"destinationIndex":2,"duration":"600s","condition":"ROUTE_EXISTS"}
+]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[
+  {"originIndex":0,"destinationIndex":0,"duration":"500s","condition":"ROUTE_EXISTS"},
+  {"originIndex":1,"destinationIndex":0,"duration":"900s","condition":"ROUTE_EXISTS"}
+]`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"coffee",
+		"--from", "A",
+		"--to", "B",
+		"--max-waypoints", "1",
+		"--max-detour", "10m",
+		"--sort", "detour",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--routes-base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stdout=%s stderr=%s)", exitCode, stdout.String(), stderr.String())
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "Near Cafe") || !strings.Contains(output, "+5 min") {
+		t.Fatalf("unexpected stdout: %s", output)
+	}
+	if strings.Contains(output, "Far Cafe") {
+		t.Fatalf("expected far place filtered out: %s", output)
+	}
+}
+
+func TestRunRouteConflictingEndpoints(t *testing.T) {
+	cases := [][]string{
+		{"--from", "place_id:origin", "--from-place-id", "other-origin", "--to", "B"},
+		{"--from", "A", "--to", "47.6,-122.3", "--to-place-id", "destination"},
+	}
+	for _, args := range cases {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		exitCode := Run(append([]string{"route", "coffee", "--api-key", "test-key", "--base-url", "http://127.0.0.1:0"}, args...), &stdout, &stderr)
+		if exitCode != 2 || !strings.Contains(stderr.String(), "set only one of") {
+			t.Fatalf("%v: expected exit code 2, got %d (stderr=%s)", args, exitCode, stderr.String())
+		}
+	}
+}
+
+func TestRunRouteInvalidSort(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route", "coffee", "--from", "A", "--to", "B", "--sort", "rating", "--api-key", "test-key",
+	}, &stdout, &stderr)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "sort_by") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func TestRunRouteDistanceSampling(t *testing.T) {
+	var searches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesComputePath:
+			_, _ = w.Write([]byte("{\"routes\":[{\"polyline\":{\"encodedPolyline\":\"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case placesSearchPath:
+			searches.Add(1)
+			_, _ = w.Write([]byte(`{"places":[]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"coffee",
+		"--from", "A",
+		"--to", "B",
+		"--spacing-m", "1000",
+		"--max-waypoints", "7",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--routes-base-url", server.URL,
+		"--json",
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	// The ~800 km route needs far more than 7 waypoints at 1 km spacing.
+	if searches.Load() != 7 {
+		t.Fatalf("expected the waypoint budget to cap searches, got %d", searches.Load())
+	}
+}
+
+func TestRunRouteShowRouteWithoutQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != routesComputePath {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"routes":[{"polyline":{"encodedPolyline":"_p~iF~ps|U_ulLnnqC_mqNvxq` + "`" + `@"},"duration":"5400s","distanceMeters":150000}]}`))
+	}))
+	defer server.Close()
+
+	args := []string{
+		"route",
+		"--from", "A",
+		"--to", "B",
+		"--show-route",
+		"--api-key", "test-key",
+		"--routes-base-url", server.URL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := Run(args, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "150.0 km") || !strings.Contains(stdout.String(), "1 h 30 min") {
+		t.Fatalf("unexpected stdout: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	exitCode = Run(append(args, "--json"), &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	var response gplace.RouteResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if response.Route == nil || response.Route.DurationSeconds != 5400 || len(response.Route.Polyline) != 3 {
+		t.Fatalf("unexpected route: %#v", response.Route)
+	}
+}
+
+func TestRunRouteInvalidAvoid(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"coffee",
+		"--from", "A",
+		"--to", "B",
+		"--avoid", "potholes",
+		"--api-key", "test-key",
+	}, &stdout, &stderr)
+
+	if exitCode != 2 {
+		t.Fatalf("expected validation error exit code 2, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "avoid") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func TestParseRouteLocation(t *testing.T) {
+	if loc := parseRouteLocation("place_id:abc"); loc.PlaceID != "abc" {
+		t.Fatalf("unexpected place ID location: %#v", loc)
+	}
+	if loc := parseRouteLocation(" 47.6, -122.3 "); loc.Location == nil || loc.Location.Lat != 47.6 || loc.Location.Lng != -122.3 {
+		t.Fatalf("unexpected coordinate location: %#v", loc)
+	}
+	if loc := parseRouteLocation("Seattle, WA"); loc.Address != "Seattle, WA" {
+		t.Fatalf("unexpected address location: %#v", loc)
+	}
+}
+
 func TestRunRouteValidationError(t *testing.T) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -332,6 +638,16 @@ func TestRunRouteValidationError(t *testing.T) {
 	}
 }
 
+func TestRunRouteRequiresQuery(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{"route", "--from", "A", "--to", "B", "--api-key", "test-key"}, &stdout, &stderr)
+	if exitCode != 2 || !strings.Contains(stderr.String(), "query") {
+		t.Fatalf("expected query validation error, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+}
+
 func TestRunRouteMissingFrom(t *testing.T) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
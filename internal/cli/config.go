@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/qztseng/gplace"
+)
+
+// Config file keys that are not flag names.
+const (
+	configProfiles = "profiles"
+	configProfile  = "profile"
+)
+
+// configResolver supplies flag values from the JSON config file. Keys are
+// flag names in snake_case, at the top level for every command or inside a
+// command object for that command and its subcommands; subcommand objects
+// nest inside their parent's ("auth": {"login": {...}}). A named profile
+// nests the same layout under "profiles". Lookup order, first match wins,
+// with the innermost command object first:
+//
+//	profiles.<profile>.<command>...<flag>
+//	profiles.<profile>.<flag>
+//	<command>...<flag>
+//	<flag>
+//
+// Command-line flags and environment variables take precedence over the
+// file. The file is read on first use, after the command line has been
+// parsed, so --config and --profile are known.
+type configResolver struct {
+	loaded  bool
+	path    string
+	values  map[string]any
+	profile string
+	err     error
+}
+
+var _ kong.Resolver = (*configResolver)(nil)
+
+// Validate is a no-op; check reports config errors after parsing so they are
+// not printed with the usage text.
+func (r *configResolver) Validate(*kong.Application) error {
+	return nil
+}
+
+// Resolve returns the configured value for flag, or nil to leave it unset.
+func (r *configResolver) Resolve(ctx *kong.Context, parent *kong.Path, flag *kong.Flag) (any, error) {
+	if flag.Name == "config" || flag.Name == configProfile {
+		return nil, nil
+	}
+	for _, env := range flag.Envs {
+		if os.Getenv(env) != "" {
+			return nil, nil
+		}
+	}
+	r.load(ctx)
+	if r.values == nil {
+		return nil, nil
+	}
+
+	key := configKey(flag.Name)
+	var path []string
+	if parent.Command != nil {
+		path = commandPath(parent.Command)
+	}
+	layers := []map[string]any{r.values}
+	if profile, ok := configObject(r.values[configProfiles])[r.profile].(map[string]any); ok {
+		layers = []map[string]any{profile, r.values}
+	}
+	for _, layer := range layers {
+		sections := []map[string]any{layer}
+		for _, name := range path {
+			section := configObject(sections[len(sections)-1][name])
+			if section == nil {
+				break
+			}
+			sections = append(sections, section)
+		}
+		for i := len(sections) - 1; i >= 0; i-- {
+			if value, ok := sections[i][key]; ok {
+				return value, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// commandPath is the names of node and its parent commands, outermost first.
+func commandPath(node *kong.Node) []string {
+	var path []string
+	for ; node != nil && node.Type == kong.CommandNode; node = node.Parent {
+		path = append([]string{node.Name}, path...)
+	}
+	return path
+}
+
+// load reads the file named by --config (or the default path) and selects
+// the profile from --profile or the file's "profile" key.
+func (r *configResolver) load(ctx *kong.Context) {
+	if r.loaded {
+		return
+	}
+	r.loaded = true
+
+	// --config is a plain string so an empty GPLACE_CONFIG means "default"
+	// rather than the working directory.
+	explicit := contextFlag(ctx, "config")
+	r.path = defaultConfigPath()
+	if explicit != "" {
+		r.path = kong.ExpandPath(explicit)
+	}
+	if r.path == "" {
+		return
+	}
+	payload, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) && explicit == "" {
+		return
+	}
+	if err != nil {
+		r.err = fmt.Errorf("gplace: read config: %w", err)
+		return
+	}
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return
+	}
+	if err := json.Unmarshal(payload, &r.values); err != nil {
+		r.err = fmt.Errorf("gplace: parse config %s: %w", r.path, err)
+		r.values = nil
+		return
+	}
+	// A file holding a key, or the command that prints one, must be as
+	// private as the credentials file.
+	if configHasSecret(r.values) {
+		if err := checkPrivateFile(r.path); err != nil {
+			r.err = err
+			r.values = nil
+			return
+		}
+	}
+
+	r.profile = contextFlag(ctx, configProfile)
+	if r.profile == "" {
+		r.profile, _ = r.values[configProfile].(string)
+	}
+}
+
+// check reports a config file that could not be read, an unknown profile or
+// keys that match no flag or command of model.
+func (r *configResolver) check(model *kong.Application, profile string) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.values == nil {
+		if profile != "" {
+			return gplace.ValidationError{Field: "profile", Message: "requires a config file"}
+		}
+		return nil
+	}
+	profiles := configObject(r.values[configProfiles])
+	if r.profile != "" {
+		if _, ok := profiles[r.profile].(map[string]any); !ok {
+			return gplace.ValidationError{Field: "profile", Message: fmt.Sprintf("%q not found in %s", r.profile, r.path)}
+		}
+	}
+
+	if err := checkConfigLayer(model, r.values, ""); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(profiles) {
+		layer, ok := profiles[name].(map[string]any)
+		if !ok {
+			return gplace.ValidationError{Field: "config", Message: fmt.Sprintf("%s.%s must be an object", configProfiles, name)}
+		}
+		if err := checkConfigLayer(model, layer, configProfiles+"."+name+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkConfigLayer checks the keys of the top level or one profile.
+func checkConfigLayer(model *kong.Application, layer map[string]any, prefix string) error {
+	return checkConfigSection(model.Node, layer, prefix, prefix == "")
+}
+
+// checkConfigSection checks one object of the config file: its keys must be
+// flags of node or of the commands below it, or objects for its subcommands,
+// checked in turn.
+func checkConfigSection(node *kong.Node, section map[string]any, prefix string, top bool) error {
+	flags := map[string]bool{}
+	collectConfigFlags(node, flags)
+	commands := map[string]*kong.Node{}
+	for _, child := range node.Children {
+		if child.Type == kong.CommandNode {
+			commands[child.Name] = child
+		}
+	}
+
+	for _, key := range sortedKeys(section) {
+		switch {
+		case top && (key == configProfiles || key == configProfile):
+		case commands[key] != nil:
+			child, ok := section[key].(map[string]any)
+			if !ok {
+				return gplace.ValidationError{Field: "config", Message: fmt.Sprintf("%s%s must be an object", prefix, key)}
+			}
+			if err := checkConfigSection(commands[key], child, prefix+key+".", false); err != nil {
+				return err
+			}
+		case !flags[key]:
+			return gplace.ValidationError{Field: "config", Message: fmt.Sprintf("unknown key %s%s", prefix, key)}
+		}
+	}
+	return nil
+}
+
+// collectConfigFlags adds the config keys of the flags of node and every
+// node below it.
+func collectConfigFlags(node *kong.Node, flags map[string]bool) {
+	for _, flag := range node.Flags {
+		flags[configKey(flag.Name)] = true
+	}
+	for _, child := range node.Children {
+		collectConfigFlags(child, flags)
+	}
+}
+
+// configHasSecret reports whether values sets api_key or api_key_cmd at any
+// level.
+func configHasSecret(values map[string]any) bool {
+	for key, value := range values {
+		if key == configKey("api-key") || key == configKey("api-key-cmd") {
+			return true
+		}
+		if section, ok := value.(map[string]any); ok && configHasSecret(section) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultConfigPath is $XDG_CONFIG_HOME/gplace/config.json, falling back to
+// ~/.config when XDG_CONFIG_HOME is unset.
+func defaultConfigPath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+// configDir is where gplace keeps its config and credentials files; it is
+// empty when no home directory is known.
+func configDir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gplace")
+}
+
+// contextFlag returns the string value of the named flag on the parsed
+// command line, including values from its environment variable.
+func contextFlag(ctx *kong.Context, name string) string {
+	for _, flag := range ctx.Flags() {
+		if flag.Name == name {
+			value, _ := ctx.FlagValue(flag).(string)
+			return value
+		}
+	}
+	return ""
+}
+
+// configKey is the config file key of a flag: its name in snake_case.
+func configKey(flag string) string {
+	return strings.ReplaceAll(flag, "-", "_")
+}
+
+func configObject(value any) map[string]any {
+	object, _ := value.(map[string]any)
+	return object
+}
+
+func sortedKeys(values map[string]any) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
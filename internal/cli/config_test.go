@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testConfig = `{
+	"timeout": "5s",
+	"language": "en",
+	"search": {"limit": 3},
+	"profiles": {
+		"work": {
+			"api_key": "work-key",
+			"region": "US",
+			"search": {"limit": 7, "local": false}
+		}
+	}
+}`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gplace", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("GPLACE_CONFIG", "")
+	t.Setenv("GPLACE_PROFILE", "")
+	t.Setenv("GOOGLE_PLACES_API_KEY", "")
+	return path
+}
+
+func TestRunConfigProfile(t *testing.T) {
+	writeTestConfig(t, testConfig)
+
+	var body map[string]any
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-Goog-Api-Key")
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"places":[]}`))
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := Run([]string{"search", "coffee", "--profile", "work", "--base-url", server.URL, "--json"}, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if apiKey != "work-key" {
+		t.Fatalf("expected profile api key, got %q", apiKey)
+	}
+	if body["pageSize"] != float64(7) || body["languageCode"] != "en" || body["regionCode"] != "US" {
+		t.Fatalf("unexpected request body: %#v", body)
+	}
+
+	// Flags beat the profile, and the environment beats the file.
+	t.Setenv("GOOGLE_PLACES_API_KEY", "env-key")
+	exitCode = Run([]string{"search", "coffee", "--profile", "work", "--limit", "2", "--base-url", server.URL, "--json"}, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if apiKey != "env-key" || body["pageSize"] != float64(2) {
+		t.Fatalf("expected flag and env to win: key=%q body=%#v", apiKey, body)
+	}
+
+	// Without a profile the top-level command section applies.
+	exitCode = Run([]string{"search", "coffee", "--base-url", server.URL, "--json"}, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if body["pageSize"] != float64(3) || body["regionCode"] != nil {
+		t.Fatalf("unexpected request body without profile: %#v", body)
+	}
+}
+
+func TestRunConfigSubcommand(t *testing.T) {
+	writeTestConfig(t, `{"auth": {"login": {"command": "echo AIza-config-key-1234"}}}`)
+	t.Setenv("GPLACE_API_KEY_CMD", "")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if exitCode := Run([]string{"auth", "login"}, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	payload, err := os.ReadFile(credentialsPath())
+	if err != nil {
+		t.Fatalf("read credentials: %v", err)
+	}
+	if !strings.Contains(string(payload), "echo AIza-config-key-1234") {
+		t.Fatalf("expected the configured command to be stored, got %s", payload)
+	}
+}
+
+func TestRunConfigSecretPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows does not report Unix permission bits")
+	}
+	path := writeTestConfig(t, testConfig)
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if exitCode := Run([]string{"dedupe", "-"}, &stdout, &stderr); exitCode != 1 || !strings.Contains(stderr.String(), "chmod 600") {
+		t.Fatalf("expected a readable config with a profile api_key to be refused, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+
+	// Without a key the file may be shared.
+	if err := os.WriteFile(path, []byte(`{"language": "en"}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	stdin, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	_ = writer.Close()
+	defer func() { _ = stdin.Close() }()
+	stderr.Reset()
+	if exitCode := run([]string{"dedupe", "-"}, stdin, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+}
+
+func TestRunConfigErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		args     []string
+		exitCode int
+		message  string
+	}{
+		{"unknown profile", testConfig, []string{"--profile", "home"}, 2, `"home" not found`},
+		{"unknown key", `{"langauge": "en"}`, nil, 2, "unknown key langauge"},
+		{"unknown command key", `{"profiles": {"work": {"search": {"radius": 5}}}}`, nil, 2, "unknown key profiles.work.search.radius"},
+		{"unknown subcommand key", `{"auth": {"login": {"radius": 5}}}`, nil, 2, "unknown key auth.login.radius"},
+		{"subcommand at top level", `{"login": {"command": "pass show places"}}`, nil, 2, "unknown key login"},
+		{"invalid json", `{"language":`, nil, 1, "parse config"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			writeTestConfig(t, tc.config)
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			args := append([]string{"dedupe", "-"}, tc.args...)
+			if exitCode := Run(args, &stdout, &stderr); exitCode != tc.exitCode {
+				t.Fatalf("expected exit code %d, got %d (stderr=%s)", tc.exitCode, exitCode, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tc.message) {
+				t.Fatalf("expected %q in stderr, got %s", tc.message, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/qztseng/gplace"
+)
+
+// dedupeStdin labels records read from stdin in the sources column.
+const dedupeStdin = "stdin"
+
+// dedupeColumns are the default csv/tsv/table columns of dedupe output.
+var dedupeColumns = append(slices.Clone(placeColumns), "place_ids", "sources")
+
+// DedupeCmd merges duplicate places from saved results.
+type DedupeCmd struct {
+	Files         []string `arg:"" optional:"" name:"file" help:"JSON or NDJSON output of search, nearby, route, resolve or batch (default or - reads stdin)."`
+	NameThreshold float64  `help:"Minimum name similarity (0-1) for records with different place IDs." default:"0.8"`
+	MaxDistanceM  float64  `help:"Max distance in meters between records of one place; 0 merges by place ID only." default:"150"`
+}
+
+func (*DedupeCmd) offline() bool { return true }
+
+// dedupeRecord is a canonical place with the inputs it was merged from.
+type dedupeRecord struct {
+	gplace.PlaceSummary
+	PlaceIDs []string `json:"place_ids"`
+	Sources  []string `json:"sources"`
+}
+
+// placesPayload holds the fields of any gplace JSON output that can carry
+// places: NDJSON and batch records, place objects and response objects.
+type placesPayload struct {
+	Type      string                 `json:"type"`
+	Data      json.RawMessage        `json:"data"`
+	PlaceID   string                 `json:"place_id"`
+	Results   []gplace.PlaceSummary  `json:"results"`
+	Places    []gplace.PlaceSummary  `json:"places"`
+	Waypoints []gplace.RouteWaypoint `json:"waypoints"`
+}
+
+// Run executes the dedupe command.
+func (c *DedupeCmd) Run(app *App) error {
+	files := c.Files
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var places []gplace.PlaceSummary
+	var sources []string
+	for _, file := range files {
+		found, err := readPlacesFile(app, file)
+		if err != nil {
+			return err
+		}
+		label := file
+		if file == "-" {
+			label = dedupeStdin
+		}
+		places = append(places, found...)
+		for range found {
+			sources = append(sources, label)
+		}
+	}
+
+	deduped, err := gplace.Dedupe(places, gplace.DedupeOptions{
+		NameThreshold: &c.NameThreshold,
+		MaxDistanceM:  &c.MaxDistanceM,
+	})
+	if err != nil {
+		return err
+	}
+	records := make([]dedupeRecord, 0, len(deduped))
+	for _, place := range deduped {
+		record := dedupeRecord{PlaceSummary: place.PlaceSummary, PlaceIDs: place.PlaceIDs}
+		for _, index := range place.Sources {
+			if !slices.Contains(record.Sources, sources[index]) {
+				record.Sources = append(record.Sources, sources[index])
+			}
+		}
+		records = append(records, record)
+	}
+
+	switch app.format {
+	case formatText:
+		return writeText(app, renderDedupe(app.color, records, len(places)))
+	case formatJSON:
+		return writeJSON(app.out, records)
+	case formatNDJSON:
+		return writeRecords(app.out, recordPlace, records)
+	case formatCSV, formatTSV, formatTable:
+		data, err := newTable(records, app.columns, dedupeColumns)
+		if err != nil {
+			return err
+		}
+		return writeTable(app, data)
+	case formatTemplate:
+		return writeTemplate(app, records)
+	default:
+		// Map and markdown formats show the canonical places.
+		canonical := make([]gplace.PlaceSummary, 0, len(records))
+		for _, record := range records {
+			canonical = append(canonical, record.PlaceSummary)
+		}
+		return outputPlaces(app, canonical)
+	}
+}
+
+// readPlacesFile reads every JSON value in file ("-" is stdin), so both
+// single documents and NDJSON streams work.
+func readPlacesFile(app *App, file string) ([]gplace.PlaceSummary, error) {
+	input := io.Reader(app.in)
+	label := dedupeStdin
+	if file != "-" {
+		handle, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("gplace: open input: %w", err)
+		}
+		defer func() { _ = handle.Close() }()
+		input = handle
+		label = file
+	}
+
+	var places []gplace.PlaceSummary
+	decoder := json.NewDecoder(input)
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return places, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gplace: read %s: %w", label, err)
+		}
+		found, err := decodePlaces(raw)
+		if err != nil {
+			return nil, fmt.Errorf("gplace: read %s: %w", label, err)
+		}
+		places = append(places, found...)
+	}
+}
+
+// decodePlaces extracts the places from one JSON value. Records that carry
+// no places (routes, suggestions, page tokens, errors) yield none.
+func decodePlaces(raw json.RawMessage) ([]gplace.PlaceSummary, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var places []gplace.PlaceSummary
+		err := json.Unmarshal(raw, &places)
+		return places, err
+	}
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, errors.New("expected a JSON object or array of places")
+	}
+
+	var payload placesPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	switch {
+	case payload.Type != "":
+		if len(payload.Data) == 0 {
+			return nil, nil
+		}
+		switch payload.Type {
+		case recordPlace, recordWaypoint, recordResult:
+			return decodePlaces(payload.Data)
+		default:
+			return nil, nil
+		}
+	case payload.PlaceID != "":
+		var place gplace.PlaceSummary
+		err := json.Unmarshal(raw, &place)
+		return []gplace.PlaceSummary{place}, err
+	case len(payload.Places) > 0:
+		// Route output lists each place once here; waypoints repeat them.
+		return payload.Places, nil
+	case len(payload.Waypoints) > 0:
+		var places []gplace.PlaceSummary
+		for _, waypoint := range payload.Waypoints {
+			places = append(places, waypoint.Results...)
+		}
+		return places, nil
+	default:
+		return payload.Results, nil
+	}
+}
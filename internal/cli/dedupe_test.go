@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDedupe(t *testing.T) {
+	dir := t.TempDir()
+	search := filepath.Join(dir, "search.json")
+	route := filepath.Join(dir, "route.ndjson")
+	searchJSON := `{"results":[
+		{"place_id":"old","name":"Blue Bottle Coffee","location":{"lat":47.6,"lng":-122.3},"user_rating_count":12},
+		{"place_id":"other","name":"Starbucks","location":{"lat":47.6,"lng":-122.3}}
+	]}`
+	routeNDJSON := `{"type":"route","data":{"distance_meters":1000}}
+{"type":"waypoint","index":0,"data":{"location":{"lat":47.6,"lng":-122.3},"results":[{"place_id":"new","name":"Blue Bottle","location":{"lat":47.6005,"lng":-122.3},"user_rating_count":340}]}}
+{"type":"place","data":{"place_id":"other","name":"Starbucks","location":{"lat":47.6,"lng":-122.3}}}
+`
+	if err := os.WriteFile(search, []byte(searchJSON), 0o600); err != nil {
+		t.Fatalf("write search: %v", err)
+	}
+	if err := os.WriteFile(route, []byte(routeNDJSON), 0o600); err != nil {
+		t.Fatalf("write route: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := Run([]string{"dedupe", search, route, "--json"}, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+
+	var records []dedupeRecord
+	if err := json.Unmarshal(stdout.Bytes(), &records); err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 places, got %#v", records)
+	}
+	if records[0].PlaceID != "new" || strings.Join(records[0].PlaceIDs, ",") != "new,old" {
+		t.Fatalf("unexpected merged place: %#v", records[0])
+	}
+	if strings.Join(records[0].Sources, ",") != search+","+route || strings.Join(records[1].Sources, ",") != search+","+route {
+		t.Fatalf("unexpected sources: %#v", records)
+	}
+
+	stdout.Reset()
+	exitCode = Run([]string{"dedupe", search, route, "--format", "csv", "--columns", "place_id,place_ids"}, &stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if stdout.String() != "place_id,place_ids\nnew,new;old\nother,other\n" {
+		t.Fatalf("unexpected csv output: %q", stdout.String())
+	}
+}
+
+func TestRunDedupeText(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	app := &App{out: &stdout, err: &stderr, format: formatText, color: NewColor(false)}
+	input := filepath.Join(t.TempDir(), "places.json")
+	payload := `[{"place_id":"a","name":"Cafe Uno","location":{"lat":1,"lng":2}},{"place_id":"a","name":"Cafe Uno"}]`
+	if err := os.WriteFile(input, []byte(payload), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	cmd := DedupeCmd{Files: []string{input}, NameThreshold: 0.8, MaxDistanceM: 150}
+	if err := cmd.Run(app); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Places (1 from 2 records)") || !strings.Contains(stdout.String(), "Sources: "+input) {
+		t.Fatalf("unexpected text output: %s", stdout.String())
+	}
+}
+
+func TestRunDedupeZeroDistance(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "places.json")
+	payload := `[{"place_id":"a","name":"Cafe Uno","location":{"lat":1,"lng":2}},{"place_id":"b","name":"Cafe Uno","location":{"lat":1,"lng":2}}]`
+	if err := os.WriteFile(input, []byte(payload), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if exitCode := Run([]string{"dedupe", input, "--max-distance-m", "0", "--no-color"}, &stdout, &stderr); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Places (2 from 2 records)") {
+		t.Fatalf("expected no proximity merging: %s", stdout.String())
+	}
+}
+
+func TestRunDedupeInvalidInput(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(input, []byte(`"coffee"`), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	if exitCode := Run([]string{"dedupe", input}, &stdout, &stderr); exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "expected a JSON object or array of places") {
+		t.Fatalf("unexpected error: %s", stderr.String())
+	}
+
+	if err := os.WriteFile(input, []byte(`[]`), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	if exitCode := Run([]string{"dedupe", input, "--name-threshold", "2"}, &stdout, &stderr); exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+}
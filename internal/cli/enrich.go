@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/qztseng/gplace"
+)
+
+// Values of the match_status column.
+const (
+	enrichMatched  = "matched"
+	enrichReview   = "review"
+	enrichNotFound = "not_found"
+	enrichError    = "error"
+)
+
+// enrichPrefix starts every added column so it cannot clash with the input.
+const enrichPrefix = "match_"
+
+// enrichColumns are added to every row, before any --fields columns.
+var enrichColumns = []string{"place_id", "name", "address", "confidence", "status", "error"}
+
+// EnrichCmd matches CSV rows to places.
+type EnrichCmd struct {
+	File          string   `arg:"" name:"file" help:"CSV file with a header row (- reads stdin)."`
+	NameCol       string   `help:"Column with the business name." default:"name"`
+	AddressCol    string   `help:"Column with the address (optional but improves matches)."`
+	Output        string   `help:"Write the enriched CSV here instead of stdout." type:"path"`
+	Review        string   `help:"Write rows below --min-confidence here instead of the output." type:"path"`
+	MinConfidence float64  `help:"Confidence (0-1) below which a match needs review." default:"0.6"`
+	Fields        []string `help:"Place details columns to add, named as in --columns (e.g. phone,website,rating). One details request per matched row."`
+	Concurrency   int      `help:"Rows matched in parallel (1-20)." default:"4"`
+	Language      string   `help:"BCP-47 language code (e.g. en, en-US)."`
+	Region        string   `help:"CLDR region code (e.g. US, DE)."`
+}
+
+type enrichResult struct {
+	status string
+	best   *gplace.MatchCandidate
+	fields map[string]string
+	err    error
+}
+
+// Run executes the enrich command.
+func (c *EnrichCmd) Run(app *App) error {
+	if app.format != formatText && app.format != formatCSV {
+		return unsupportedFormat(app.format, "enrich")
+	}
+	if c.MinConfidence < 0 || c.MinConfidence > 1 {
+		return gplace.ValidationError{Field: "min_confidence", Message: "must be 0-1"}
+	}
+	if c.Concurrency < 1 || c.Concurrency > maxCommandWorkers {
+		return gplace.ValidationError{Field: "concurrency", Message: fmt.Sprintf("must be 1-%d", maxCommandWorkers)}
+	}
+	if _, err := newTable([]gplace.PlaceDetails{}, c.Fields, nil); err != nil {
+		return err
+	}
+
+	header, rows, err := c.readRows(app)
+	if err != nil {
+		return err
+	}
+	nameIndex := slices.Index(header, c.NameCol)
+	if nameIndex < 0 {
+		return gplace.ValidationError{Field: "name_col", Message: fmt.Sprintf("column %q not found", c.NameCol)}
+	}
+	addressIndex := -1
+	if c.AddressCol != "" {
+		if addressIndex = slices.Index(header, c.AddressCol); addressIndex < 0 {
+			return gplace.ValidationError{Field: "address_col", Message: fmt.Sprintf("column %q not found", c.AddressCol)}
+		}
+	}
+
+	results := make([]enrichResult, len(rows))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range c.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				address := ""
+				if addressIndex >= 0 {
+					address = rows[i][addressIndex]
+				}
+				results[i] = c.enrichRow(context.Background(), app.client, rows[i][nameIndex], address)
+			}
+		}()
+	}
+	for i := range rows {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	output := app.out
+	if c.Output != "" {
+		file, err := os.Create(c.Output)
+		if err != nil {
+			return fmt.Errorf("gplace: create output: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		output = file
+	}
+	out := csv.NewWriter(output)
+	var review *csv.Writer
+	if c.Review != "" {
+		file, err := os.Create(c.Review)
+		if err != nil {
+			return fmt.Errorf("gplace: create review file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		review = csv.NewWriter(file)
+	}
+
+	added := make([]string, 0, len(enrichColumns)+len(c.Fields))
+	for _, column := range append(slices.Clone(enrichColumns), c.Fields...) {
+		added = append(added, enrichPrefix+column)
+	}
+	writers := []*csv.Writer{out}
+	if review != nil {
+		writers = append(writers, review)
+	}
+	for _, writer := range writers {
+		if err := writer.Write(append(slices.Clone(header), added...)); err != nil {
+			return err
+		}
+	}
+
+	counts := map[string]int{}
+	for i, row := range rows {
+		result := results[i]
+		counts[result.status]++
+		record := append(slices.Clone(row), result.cells(c.Fields)...)
+		writer := out
+		if review != nil && result.status != enrichMatched {
+			writer = review
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	for _, writer := range writers {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintf(app.err, "enriched %d rows: %d matched, %d review, %d not found, %d errors\n",
+		len(rows), counts[enrichMatched], counts[enrichReview], counts[enrichNotFound], counts[enrichError])
+	return nil
+}
+
+func (c *EnrichCmd) readRows(app *App) ([]string, [][]string, error) {
+	input := io.Reader(app.in)
+	if c.File != "-" {
+		file, err := os.Open(c.File)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gplace: open input: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		input = file
+	}
+	reader := csv.NewReader(input)
+	// CRM exports often have ragged rows; missing cells read as empty, so
+	// short rows are padded to keep the added columns under their headers.
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("gplace: input has no header row")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("gplace: read input: %w", err)
+	}
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return header, rows, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("gplace: read input: %w", err)
+		}
+		if len(row) > len(header) {
+			line, _ := reader.FieldPos(0)
+			return nil, nil, fmt.Errorf("gplace: input line %d has %d cells but the header has %d", line, len(row), len(header))
+		}
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		rows = append(rows, row)
+	}
+}
+
+// enrichRow matches one row and, for confident matches, fetches the
+// requested details fields.
+func (c *EnrichCmd) enrichRow(ctx context.Context, client *gplace.Client, name string, address string) enrichResult {
+	response, err := client.Match(ctx, gplace.MatchRequest{
+		Name:     name,
+		Address:  address,
+		Language: c.Language,
+		Region:   c.Region,
+	})
+	if err != nil {
+		return enrichResult{status: enrichError, err: err}
+	}
+	if response.Best == nil {
+		return enrichResult{status: enrichNotFound}
+	}
+	result := enrichResult{status: enrichMatched, best: response.Best}
+	if response.Best.Confidence < c.MinConfidence {
+		// Details for uncertain matches would be paid for and likely discarded.
+		result.status = enrichReview
+		return result
+	}
+	if len(c.Fields) == 0 {
+		return result
+	}
+
+	details, err := client.DetailsWithOptions(ctx, gplace.DetailsRequest{
+		PlaceID:  response.Best.Place.PlaceID,
+		Language: c.Language,
+		Region:   c.Region,
+	})
+	if err != nil {
+		result.status = enrichError
+		result.err = err
+		return result
+	}
+	data, err := newTable([]gplace.PlaceDetails{details}, c.Fields, nil)
+	if err != nil {
+		result.status = enrichError
+		result.err = err
+		return result
+	}
+	result.fields = data.rows[0]
+	return result
+}
+
+// cells returns the added column values in enrichColumns order, then fields.
+func (r enrichResult) cells(fields []string) []string {
+	cells := make([]string, 0, len(enrichColumns)+len(fields))
+	if r.best != nil {
+		cells = append(cells,
+			r.best.Place.PlaceID,
+			r.best.Place.Name,
+			r.best.Place.Address,
+			strconv.FormatFloat(r.best.Confidence, 'f', 2, 64),
+		)
+	} else {
+		cells = append(cells, "", "", "", "")
+	}
+	errText := ""
+	if r.err != nil {
+		errText = r.err.Error()
+	}
+	cells = append(cells, r.status, errText)
+	for _, field := range fields {
+		cells = append(cells, r.fields[field])
+	}
+	return cells
+}
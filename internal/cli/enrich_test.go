@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunEnrich(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/places/") {
+			_, _ = w.Write([]byte(`{"id":"blue","displayName":{"text":"Blue Bottle Coffee"},"nationalPhoneNumber":"(206) 555-0100","rating":4.6}`))
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		query, _ := body["textQuery"].(string)
+		switch {
+		case query == "1 Main St" || query == "2 Side St":
+			// Address lookups for the reference location.
+			_, _ = w.Write([]byte(`{"places":[{"id":"addr","location":{"latitude":47.6,"longitude":-122.3}}]}`))
+		case strings.HasPrefix(query, "Blue Bottle"):
+			_, _ = w.Write([]byte(`{"places":[{"id":"blue","displayName":{"text":"Blue Bottle Coffee"},"formattedAddress":"1 Main St","location":{"latitude":47.6,"longitude":-122.3}}]}`))
+		case strings.HasPrefix(query, "Acme"):
+			_, _ = w.Write([]byte(`{"places":[{"id":"zz","displayName":{"text":"Zebra Zone"},"location":{"latitude":48,"longitude":-122.3}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"places":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "crm.csv")
+	reviewPath := filepath.Join(dir, "review.csv")
+	content := "id,name,address\n1,Blue Bottle Coffee,1 Main St\n2,Acme Widgets,2 Side St\n3,Nothing Here,\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"enrich", input,
+		"--address-col", "address",
+		"--fields", "phone,rating",
+		"--review", reviewPath,
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	expectedHeader := "id,name,address,match_place_id,match_name,match_address,match_confidence,match_status,match_error,match_phone,match_rating"
+	if len(records) != 2 || strings.Join(records[0], ",") != expectedHeader {
+		t.Fatalf("unexpected output: %v", records)
+	}
+	if strings.Join(records[1], ",") != "1,Blue Bottle Coffee,1 Main St,blue,Blue Bottle Coffee,1 Main St,1.00,matched,,(206) 555-0100,4.6" {
+		t.Fatalf("unexpected matched row: %v", records[1])
+	}
+
+	payload, err := os.ReadFile(reviewPath)
+	if err != nil {
+		t.Fatalf("read review file: %v", err)
+	}
+	review, err := csv.NewReader(bytes.NewReader(payload)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse review file: %v", err)
+	}
+	if len(review) != 3 || review[1][3] != "zz" || review[1][7] != "review" || review[2][7] != "not_found" {
+		t.Fatalf("unexpected review rows: %v", review)
+	}
+	if !strings.Contains(stderr.String(), "1 matched, 1 review, 1 not found") {
+		t.Fatalf("unexpected summary: %s", stderr.String())
+	}
+}
+
+func TestRunEnrichValidation(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "crm.csv")
+	if err := os.WriteFile(input, []byte("company\nAcme\n"), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	for _, args := range [][]string{
+		{"enrich", input},
+		{"enrich", input, "--name-col", "company", "--fields", "phnoe"},
+		{"enrich", input, "--name-col", "company", "--min-confidence", "2"},
+	} {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		if exitCode := Run(append(args, "--api-key", "test-key"), &stdout, &stderr); exitCode != 2 {
+			t.Fatalf("%v: expected exit code 2, got %d (%s)", args, exitCode, stderr.String())
+		}
+	}
+}
+
+func TestRunEnrichRaggedRows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"places":[]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "crm.csv")
+	if err := os.WriteFile(input, []byte("id,name,address,notes\n1,Nothing Here\n"), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"enrich", input, "--api-key", "test-key", "--base-url", server.URL}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	status := map[string]string{}
+	for i, column := range records[0] {
+		status[column] = records[1][i]
+	}
+	if len(records[1]) != len(records[0]) || status["notes"] != "" || status["match_status"] != enrichNotFound {
+		t.Fatalf("expected the short row padded under its headers, got %v", records)
+	}
+
+	if err := os.WriteFile(input, []byte("id,name\n1,Blue Bottle\n2,Acme,extra\n"), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	stderr.Reset()
+	if code := Run([]string{"enrich", input, "--api-key", "test-key", "--base-url", server.URL}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "line 3") {
+		t.Fatalf("expected a line-numbered error for a long row, got %d (stderr=%s)", code, stderr.String())
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+
+	"github.com/qztseng/gplace"
+)
+
+// GeoJSON (RFC 7946) types. Coordinates are [lng, lat].
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+	Properties map[string]any   `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// featureBuilder collects features, keeping the first marshal error.
+type featureBuilder struct {
+	features []geoJSONFeature
+	err      error
+}
+
+// add appends a feature whose properties are value's JSON fields, minus the
+// fields already encoded in the geometry, plus extra.
+func (b *featureBuilder) add(geometry *geoJSONGeometry, value any, omit []string, extra map[string]any) {
+	properties := map[string]any{}
+	if value != nil {
+		payload, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(payload, &properties)
+		}
+		if err != nil {
+			if b.err == nil {
+				b.err = err
+			}
+			return
+		}
+	}
+	for _, key := range omit {
+		delete(properties, key)
+	}
+	for key, extraValue := range extra {
+		properties[key] = extraValue
+	}
+	b.features = append(b.features, geoJSONFeature{Type: "Feature", Geometry: geometry, Properties: properties})
+}
+
+func (b *featureBuilder) collection() (geoJSONCollection, error) {
+	features := b.features
+	if features == nil {
+		features = []geoJSONFeature{}
+	}
+	return geoJSONCollection{Type: "FeatureCollection", Features: features}, b.err
+}
+
+// pointGeometry returns nil for a missing location; GeoJSON allows null geometry.
+func pointGeometry(loc *gplace.LatLng) *geoJSONGeometry {
+	if loc == nil {
+		return nil
+	}
+	return &geoJSONGeometry{Type: "Point", Coordinates: []float64{loc.Lng, loc.Lat}}
+}
+
+func lineGeometry(points []gplace.LatLng) *geoJSONGeometry {
+	coordinates := make([][]float64, 0, len(points))
+	for _, point := range points {
+		coordinates = append(coordinates, []float64{point.Lng, point.Lat})
+	}
+	return &geoJSONGeometry{Type: "LineString", Coordinates: coordinates}
+}
+
+var locationField = []string{"location"}
+
+func placeFeatures(places []gplace.PlaceSummary) (geoJSONCollection, error) {
+	var builder featureBuilder
+	for _, place := range places {
+		builder.add(pointGeometry(place.Location), place, locationField, nil)
+	}
+	return builder.collection()
+}
+
+func detailsFeatures(place gplace.PlaceDetails) (geoJSONCollection, error) {
+	var builder featureBuilder
+	builder.add(pointGeometry(place.Location), place, locationField, nil)
+	return builder.collection()
+}
+
+func resolvedFeatures(locations []gplace.ResolvedLocation) (geoJSONCollection, error) {
+	var builder featureBuilder
+	for _, location := range locations {
+		builder.add(pointGeometry(location.Location), location, locationField, nil)
+	}
+	return builder.collection()
+}
+
+// routeFeatures emits the route LineString, then sampled waypoints, then
+// places; a "kind" property tells them apart.
+func routeFeatures(response gplace.RouteResponse) (geoJSONCollection, error) {
+	var builder featureBuilder
+	if response.Route != nil {
+		builder.add(lineGeometry(response.Route.Polyline), response.Route, []string{"polyline"}, map[string]any{"kind": "route"})
+	}
+	for i, waypoint := range response.Waypoints {
+		placeIDs := make([]string, 0, len(waypoint.Results))
+		for _, place := range waypoint.Results {
+			placeIDs = append(placeIDs, place.PlaceID)
+		}
+		location := waypoint.Location
+		builder.add(pointGeometry(&location), nil, nil, map[string]any{
+			"kind":         "waypoint",
+			"index":        i,
+			"result_count": len(waypoint.Results),
+			"place_ids":    placeIDs,
+		})
+	}
+	for _, place := range response.Places {
+		builder.add(pointGeometry(place.Location), place, locationField, map[string]any{"kind": "place"})
+	}
+	return builder.collection()
+}
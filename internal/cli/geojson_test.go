@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+func TestPlaceFeatures(t *testing.T) {
+	rating := 4.5
+	collection, err := placeFeatures([]gplace.PlaceSummary{
+		{PlaceID: "abc", Name: "Cafe", Rating: &rating, Location: &gplace.LatLng{Lat: 47.6, Lng: -122.3}},
+		{PlaceID: "nowhere"},
+	})
+	if err != nil {
+		t.Fatalf("features error: %v", err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 {
+		t.Fatalf("unexpected collection: %#v", collection)
+	}
+
+	first := collection.Features[0]
+	coordinates, ok := first.Geometry.Coordinates.([]float64)
+	if first.Geometry.Type != "Point" || !ok || coordinates[0] != -122.3 || coordinates[1] != 47.6 {
+		t.Fatalf("expected [lng, lat] point: %#v", first.Geometry)
+	}
+	if first.Properties["place_id"] != "abc" || first.Properties["rating"] != 4.5 {
+		t.Fatalf("unexpected properties: %#v", first.Properties)
+	}
+	if _, ok := first.Properties["location"]; ok {
+		t.Fatalf("location should only appear in the geometry: %#v", first.Properties)
+	}
+	if collection.Features[1].Geometry != nil {
+		t.Fatalf("expected null geometry without a location")
+	}
+}
+
+func TestRouteFeatures(t *testing.T) {
+	index := 0
+	collection, err := routeFeatures(gplace.RouteResponse{
+		Route: &gplace.RouteInfo{
+			DistanceMeters:  1000,
+			EncodedPolyline: "??_ibE_ibE",
+			Polyline:        []gplace.LatLng{{Lat: 0, Lng: 0}, {Lat: 1, Lng: 1}},
+		},
+		Waypoints: []gplace.RouteWaypoint{
+			{Location: gplace.LatLng{Lat: 0.5, Lng: 0.5}, Results: []gplace.PlaceSummary{{PlaceID: "abc"}}},
+		},
+		Places: []gplace.RoutePlace{
+			{PlaceSummary: gplace.PlaceSummary{PlaceID: "abc", Location: &gplace.LatLng{Lat: 0.5, Lng: 0.6}}, WaypointIndex: &index},
+		},
+	})
+	if err != nil {
+		t.Fatalf("features error: %v", err)
+	}
+	if len(collection.Features) != 3 {
+		t.Fatalf("expected route, waypoint and place features: %#v", collection.Features)
+	}
+
+	route, waypoint, place := collection.Features[0], collection.Features[1], collection.Features[2]
+	if route.Geometry.Type != "LineString" || route.Properties["kind"] != "route" || route.Properties["distance_meters"] != 1000.0 {
+		t.Fatalf("unexpected route feature: %#v", route)
+	}
+	if _, ok := route.Properties["polyline"]; ok {
+		t.Fatalf("decoded polyline should only appear in the geometry")
+	}
+	if waypoint.Properties["kind"] != "waypoint" || waypoint.Properties["result_count"] != 1 {
+		t.Fatalf("unexpected waypoint feature: %#v", waypoint)
+	}
+	if place.Properties["kind"] != "place" || place.Properties["waypoint_index"] != 0.0 {
+		t.Fatalf("unexpected place feature: %#v", place)
+	}
+}
+
+func TestRunSearchGeoJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"places": [{"id": "abc", "displayName": {"text": "Cafe"}, "location": {"latitude": 1.5, "longitude": 2.5}}]}`))
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--format", "geojson",
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string    `json:"type"`
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &collection); err != nil {
+		t.Fatalf("decode output: %v (%s)", err, stdout.String())
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+		t.Fatalf("unexpected output: %s", stdout.String())
+	}
+	feature := collection.Features[0]
+	if feature.Geometry.Coordinates[0] != 2.5 || feature.Properties["name"] != "Cafe" {
+		t.Fatalf("unexpected feature: %#v", feature)
+	}
+}
+
+func TestRunAutocompleteGeoJSONUnsupported(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{"autocomplete", "caf", "--api-key", "test-key", "--format", "geojson"}, &stdout, &stderr)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "geojson is not supported for autocomplete") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const gpxNamespace = "http://www.topografix.com/GPX/1/1"
+
+// GPX 1.1 elements; field order follows the schema's sequence.
+type gpxDocument struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	XMLNS     string        `xml:"xmlns,attr"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Tracks    []gpxTrack    `xml:"trk"`
+}
+
+type gpxWaypoint struct {
+	Lat         float64  `xml:"lat,attr"`
+	Lon         float64  `xml:"lon,attr"`
+	Name        string   `xml:"name,omitempty"`
+	Description string   `xml:"desc,omitempty"`
+	Link        *gpxLink `xml:"link,omitempty"`
+	Type        string   `xml:"type,omitempty"`
+}
+
+type gpxLink struct {
+	Href string `xml:"href,attr"`
+	Text string `xml:"text,omitempty"`
+}
+
+type gpxTrack struct {
+	Name    string          `xml:"name,omitempty"`
+	Segment gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxTrackPoint `xml:"trkpt"`
+}
+
+type gpxTrackPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+func writeGPX(writer io.Writer, export mapExport) error {
+	document := gpxDocument{XMLNS: gpxNamespace, Version: "1.1", Creator: "gplace"}
+	for _, point := range export.Points {
+		waypoint := gpxWaypoint{
+			Lat:         point.Location.Lat,
+			Lon:         point.Location.Lng,
+			Name:        point.Name,
+			Description: point.description(),
+			Type:        point.Type,
+		}
+		if point.MapsURI != "" {
+			waypoint.Link = &gpxLink{Href: point.MapsURI, Text: "Google Maps"}
+		}
+		document.Waypoints = append(document.Waypoints, waypoint)
+	}
+	if len(export.Track) > 0 {
+		track := gpxTrack{Name: "Route"}
+		for _, point := range export.Track {
+			track.Segment.Points = append(track.Segment.Points, gpxTrackPoint{Lat: point.Lat, Lon: point.Lng})
+		}
+		document.Tracks = append(document.Tracks, track)
+	}
+	return writeXML(writer, document)
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/qztseng/gplace"
+)
+
+// jsonSchema is the subset of JSON Schema used by tool definitions.
+type jsonSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// schemaDoc documents one request field. Enum and bounds of an array field
+// apply to its items.
+type schemaDoc struct {
+	description string
+	enum        []any
+	minimum     *float64
+	maximum     *float64
+}
+
+// schemaDocs documents request fields by JSON name; a "Type.field" key
+// overrides the plain name for one struct.
+var schemaDocs = map[string]schemaDoc{
+	"query":                        {description: `Text query, e.g. "ramen in Shibuya".`},
+	"RouteRequest.query":           {description: `What to find along the route, e.g. "gas station".`},
+	"filters":                      {description: "Optional search refinements."},
+	"keyword":                      {description: "Keyword appended to the query."},
+	"types":                        {description: "Place type to search for, e.g. restaurant or ramen_restaurant (Google Places Table A)."},
+	"open_now":                     {description: "Only return places that are open now."},
+	"min_rating":                   {description: "Minimum rating.", minimum: schemaBound(0), maximum: schemaBound(5)},
+	"price_levels":                 {description: "Price levels: 0 free, 1 inexpensive, 2 moderate, 3 expensive, 4 very expensive.", enum: []any{0, 1, 2, 3, 4}},
+	"location_bias":                {description: "Prefer results inside this circle."},
+	"location_restriction":         {description: "Required: only return places inside this circle."},
+	"lat":                          {description: "Latitude in degrees.", minimum: schemaBound(-90), maximum: schemaBound(90)},
+	"lng":                          {description: "Longitude in degrees.", minimum: schemaBound(-180), maximum: schemaBound(180)},
+	"radius_m":                     {description: "Circle radius in meters."},
+	"RouteRequest.radius_m":        {description: "Search radius around each waypoint in meters (default 1000)."},
+	"limit":                        {description: "Max results.", minimum: schemaBound(1), maximum: schemaBound(20)},
+	"AutocompleteRequest.limit":    {description: "Max suggestions.", minimum: schemaBound(1), maximum: schemaBound(20)},
+	"LocationResolveRequest.limit": {description: "Max candidates.", minimum: schemaBound(1), maximum: schemaBound(10)},
+	"RouteRequest.limit":           {description: "Max results per waypoint.", minimum: schemaBound(1), maximum: schemaBound(20)},
+	"page_token":                   {description: "next_page_token from a previous response."},
+	"language":                     {description: "BCP-47 language code, e.g. en or ja."},
+	"region":                       {description: "CLDR region code, e.g. US or JP."},
+	"along_route":                  {description: "Rank results along an encoded route polyline instead of around a point."},
+	"encoded_polyline":             {description: "Encoded route polyline."},
+	"origin":                       {description: "Route origin; adds routing summaries to results."},
+	"travel_mode":                  {description: "Travel mode of the route.", enum: travelModeEnum},
+	"input":                        {description: "Text typed so far."},
+	"session_token":                {description: "Autocomplete session token; pass the same token to details to close the session."},
+	"included_types":               {description: "Place types to include, e.g. restaurant (Google Places Table A)."},
+	"excluded_types":               {description: "Place types to exclude (Google Places Table A)."},
+	"place_id":                     {description: "Place ID from a search, nearby or autocomplete result."},
+	"include_reviews":              {description: "Include user reviews (Enterprise SKU)."},
+	"location_text":                {description: "Address, landmark or area to resolve."},
+	"from":                         {description: "Origin address; or set from_place_id or from_location."},
+	"from_place_id":                {description: "Origin place ID."},
+	"from_location":                {description: "Origin coordinates."},
+	"to":                           {description: "Destination address; or set to_place_id or to_location."},
+	"to_place_id":                  {description: "Destination place ID."},
+	"to_location":                  {description: "Destination coordinates."},
+	"via":                          {description: "Intermediate stops, each with one of address, place_id or location."},
+	"address":                      {description: "Stop address."},
+	"RouteLocation.place_id":       {description: "Stop place ID."},
+	"location":                     {description: "Stop coordinates."},
+	"mode":                         {description: "Travel mode (default DRIVE).", enum: travelModeEnum},
+	"strategy":                     {description: "sample searches around waypoints; native searches along the route polyline (default sample).", enum: []any{gplace.RouteStrategySample, gplace.RouteStrategyNative}},
+	"modifiers":                    {description: "Route features to avoid (DRIVE and TWO_WHEELER only)."},
+	"avoid_tolls":                  {description: "Avoid toll roads."},
+	"avoid_highways":               {description: "Avoid highways."},
+	"avoid_ferries":                {description: "Avoid ferries."},
+	"departure_time":               {description: "Departure time (RFC 3339)."},
+	"max_waypoints":                {description: "Max sampled waypoints, one search each.", minimum: schemaBound(1), maximum: schemaBound(100)},
+	"sampling":                     {description: "Waypoint placement for the sample strategy: even, every spacing_m (distance), or dense.", enum: []any{gplace.RouteSamplingEven, gplace.RouteSamplingDistance, gplace.RouteSamplingDense}},
+	"spacing_m":                    {description: "Meters between waypoints for distance and dense sampling."},
+	"concurrency":                  {description: "Parallel waypoint searches.", minimum: schemaBound(1), maximum: schemaBound(20)},
+	"detours":                      {description: "Compute detour time and distance for each place."},
+	"max_detour_seconds":           {description: "Drop places with a longer or unknown detour.", minimum: schemaBound(0)},
+	"sort_by":                      {description: "Order places by position along the route or by detour.", enum: []any{gplace.RouteSortRoute, gplace.RouteSortDetour}},
+}
+
+var travelModeEnum = []any{"DRIVE", "WALK", "BICYCLE", "TWO_WHEELER", "TRANSIT"}
+
+func schemaBound(value float64) *float64 {
+	return &value
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// schemaFor derives the JSON Schema of a request type from its JSON tags.
+// Fields without omitempty are required, and objects reject unknown keys
+// like decodeRequest does.
+func schemaFor(typ reflect.Type) *jsonSchema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case typ.Kind() == reflect.Struct:
+		return structSchema(typ)
+	case typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array:
+		return &jsonSchema{Type: "array", Items: schemaFor(typ.Elem())}
+	case typ.Kind() == reflect.Map:
+		return &jsonSchema{Type: "object"}
+	case typ.Kind() == reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case typ.Kind() == reflect.String:
+		return &jsonSchema{Type: "string"}
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	default:
+		return &jsonSchema{}
+	}
+}
+
+func structSchema(typ reflect.Type) *jsonSchema {
+	closed := false
+	schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: &closed}
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := schemaFor(field.Type)
+		doc, ok := schemaDocs[typ.Name()+"."+name]
+		if !ok {
+			doc = schemaDocs[name]
+		}
+		property.Description = doc.description
+		target := property
+		if property.Items != nil {
+			target = property.Items
+		}
+		target.Enum, target.Minimum, target.Maximum = doc.enum, doc.minimum, doc.maximum
+		schema.Properties[name] = property
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/qztseng/gplace"
+)
+
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"kml"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Document kmlBody  `xml:"Document"`
+}
+
+type kmlBody struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string         `xml:"name"`
+	Address      string         `xml:"address,omitempty"`
+	Description  string         `xml:"description,omitempty"`
+	ExtendedData *kmlData       `xml:"ExtendedData,omitempty"`
+	Point        *kmlPoint      `xml:"Point,omitempty"`
+	LineString   *kmlLineString `xml:"LineString,omitempty"`
+}
+
+// kmlData becomes table columns when imported into Google My Maps.
+type kmlData struct {
+	Fields []kmlField `xml:"Data"`
+}
+
+type kmlField struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+func writeKML(writer io.Writer, export mapExport) error {
+	document := kmlDocument{XMLNS: kmlNamespace, Document: kmlBody{Name: "gplace"}}
+	if len(export.Track) > 0 {
+		document.Document.Placemarks = append(document.Document.Placemarks, kmlPlacemark{
+			Name:       "Route",
+			LineString: &kmlLineString{Tessellate: 1, Coordinates: kmlCoordinates(export.Track...)},
+		})
+	}
+	for _, point := range export.Points {
+		document.Document.Placemarks = append(document.Document.Placemarks, kmlPlacemark{
+			Name:         point.Name,
+			Address:      point.Address,
+			Description:  point.description(),
+			ExtendedData: kmlExtendedData(point),
+			Point:        &kmlPoint{Coordinates: kmlCoordinates(point.Location)},
+		})
+	}
+	return writeXML(writer, document)
+}
+
+func kmlExtendedData(point mapPoint) *kmlData {
+	fields := []kmlField{{Name: "place_id", Value: point.PlaceID}}
+	if point.Type != "" {
+		fields = append(fields, kmlField{Name: "type", Value: point.Type})
+	}
+	if point.Rating != nil {
+		fields = append(fields, kmlField{Name: "rating", Value: strconv.FormatFloat(*point.Rating, 'f', -1, 64)})
+	}
+	if point.RatingCount != nil {
+		fields = append(fields, kmlField{Name: "user_rating_count", Value: strconv.Itoa(*point.RatingCount)})
+	}
+	if point.MapsURI != "" {
+		fields = append(fields, kmlField{Name: "google_maps_uri", Value: point.MapsURI})
+	}
+	return &kmlData{Fields: fields}
+}
+
+// kmlCoordinates formats points as space-separated "lng,lat" tuples.
+func kmlCoordinates(points ...gplace.LatLng) string {
+	tuples := make([]string, 0, len(points))
+	for _, point := range points {
+		tuples = append(tuples, strconv.FormatFloat(point.Lng, 'f', -1, 64)+","+strconv.FormatFloat(point.Lat, 'f', -1, 64))
+	}
+	return strings.Join(tuples, " ")
+}
+
+func writeXML(writer io.Writer, value any) error {
+	payload, err := xml.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	_, err = writer.Write(append(payload, '\n'))
+	return err
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/qztseng/gplace"
+)
+
+// mapExport is the shared input of the KML and GPX writers: placemarks plus
+// an optional route track.
+type mapExport struct {
+	Points []mapPoint
+	Track  []gplace.LatLng
+}
+
+type mapPoint struct {
+	PlaceID     string
+	Name        string
+	Address     string
+	Type        string
+	MapsURI     string
+	Rating      *float64
+	RatingCount *int
+	Location    gplace.LatLng
+}
+
+// description is the free-text summary shown by map apps.
+func (p mapPoint) description() string {
+	parts := make([]string, 0, 3)
+	if p.Address != "" {
+		parts = append(parts, p.Address)
+	}
+	if p.Rating != nil {
+		rating := fmt.Sprintf("Rating: %.1f", *p.Rating)
+		if p.RatingCount != nil {
+			rating += fmt.Sprintf(" (%d)", *p.RatingCount)
+		}
+		parts = append(parts, rating)
+	}
+	if p.MapsURI != "" {
+		parts = append(parts, p.MapsURI)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// placesExport skips places without a location, which cannot be mapped; the
+// other export builders do the same.
+func placesExport(places []gplace.PlaceSummary) mapExport {
+	var export mapExport
+	for _, place := range places {
+		if place.Location == nil {
+			continue
+		}
+		export.Points = append(export.Points, summaryPoint(place))
+	}
+	return export
+}
+
+func detailsExport(place gplace.PlaceDetails) mapExport {
+	var export mapExport
+	if place.Location == nil {
+		return export
+	}
+	mapsURI := place.GoogleMapsURI
+	if mapsURI == "" {
+		mapsURI = placeMapsURL(place.Name, place.PlaceID)
+	}
+	placeType := place.PrimaryTypeDisplayName
+	if placeType == "" {
+		placeType = place.PrimaryType
+	}
+	export.Points = append(export.Points, mapPoint{
+		PlaceID:     place.PlaceID,
+		Name:        place.Name,
+		Address:     place.Address,
+		Type:        placeType,
+		MapsURI:     mapsURI,
+		Rating:      place.Rating,
+		RatingCount: place.UserRatingCount,
+		Location:    *place.Location,
+	})
+	return export
+}
+
+func resolvedExport(locations []gplace.ResolvedLocation) mapExport {
+	var export mapExport
+	for _, location := range locations {
+		if location.Location == nil {
+			continue
+		}
+		export.Points = append(export.Points, mapPoint{
+			PlaceID:  location.PlaceID,
+			Name:     location.Name,
+			Address:  location.Address,
+			Type:     firstType(location.Types),
+			MapsURI:  placeMapsURL(location.Name, location.PlaceID),
+			Location: *location.Location,
+		})
+	}
+	return export
+}
+
+func routeExport(response gplace.RouteResponse) mapExport {
+	var export mapExport
+	if response.Route != nil {
+		export.Track = response.Route.Polyline
+	}
+	for _, place := range response.Places {
+		if place.Location == nil {
+			continue
+		}
+		export.Points = append(export.Points, summaryPoint(place.PlaceSummary))
+	}
+	return export
+}
+
+func summaryPoint(place gplace.PlaceSummary) mapPoint {
+	return mapPoint{
+		PlaceID:     place.PlaceID,
+		Name:        place.Name,
+		Address:     place.Address,
+		Type:        firstType(place.Types),
+		MapsURI:     placeMapsURL(place.Name, place.PlaceID),
+		Rating:      place.Rating,
+		RatingCount: place.UserRatingCount,
+		Location:    *place.Location,
+	}
+}
+
+// placeMapsURL builds a Google Maps URL for a place ID, so exports carry a
+// link without requesting googleMapsUri in searches.
+func placeMapsURL(name string, placeID string) string {
+	if placeID == "" {
+		return ""
+	}
+	query := name
+	if query == "" {
+		query = placeID
+	}
+	values := url.Values{}
+	values.Set("api", "1")
+	values.Set("query", query)
+	values.Set("query_place_id", placeID)
+	return "https://www.google.com/maps/search/?" + values.Encode()
+}
+
+func firstType(types []string) string {
+	if len(types) == 0 {
+		return ""
+	}
+	return types[0]
+}
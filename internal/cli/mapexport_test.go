@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+func testMapExport() mapExport {
+	rating := 4.5
+	count := 120
+	return mapExport{
+		Points: []mapPoint{{
+			PlaceID:     "abc",
+			Name:        "Cafe & Co",
+			Address:     "1 Main St",
+			Type:        "cafe",
+			MapsURI:     placeMapsURL("Cafe & Co", "abc"),
+			Rating:      &rating,
+			RatingCount: &count,
+			Location:    gplace.LatLng{Lat: 47.6, Lng: -122.3},
+		}},
+		Track: []gplace.LatLng{{Lat: 47.6, Lng: -122.3}, {Lat: 45.5, Lng: -122.7}},
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeKML(&out, testMapExport()); err != nil {
+		t.Fatalf("write kml: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Fatalf("expected XML header: %s", out.String())
+	}
+
+	var document kmlDocument
+	if err := xml.Unmarshal(out.Bytes(), &document); err != nil {
+		t.Fatalf("parse kml: %v", err)
+	}
+	placemarks := document.Document.Placemarks
+	if len(placemarks) != 2 || placemarks[0].LineString == nil {
+		t.Fatalf("expected route then place placemarks: %#v", placemarks)
+	}
+	if placemarks[0].LineString.Coordinates != "-122.3,47.6 -122.7,45.5" {
+		t.Fatalf("unexpected route coordinates: %s", placemarks[0].LineString.Coordinates)
+	}
+	place := placemarks[1]
+	if place.Name != "Cafe & Co" || place.Address != "1 Main St" || place.Point.Coordinates != "-122.3,47.6" {
+		t.Fatalf("unexpected placemark: %#v", place)
+	}
+	if !strings.Contains(place.Description, "Rating: 4.5 (120)") || !strings.Contains(place.Description, "query_place_id=abc") {
+		t.Fatalf("unexpected description: %s", place.Description)
+	}
+	fields := map[string]string{}
+	for _, field := range place.ExtendedData.Fields {
+		fields[field.Name] = field.Value
+	}
+	if fields["place_id"] != "abc" || fields["rating"] != "4.5" || fields["user_rating_count"] != "120" {
+		t.Fatalf("unexpected extended data: %#v", fields)
+	}
+}
+
+func TestWriteGPX(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeGPX(&out, testMapExport()); err != nil {
+		t.Fatalf("write gpx: %v", err)
+	}
+
+	var document gpxDocument
+	if err := xml.Unmarshal(out.Bytes(), &document); err != nil {
+		t.Fatalf("parse gpx: %v", err)
+	}
+	if document.Version != "1.1" || document.XMLNS != gpxNamespace {
+		t.Fatalf("unexpected gpx root: %#v", document)
+	}
+	if len(document.Waypoints) != 1 || len(document.Tracks) != 1 {
+		t.Fatalf("expected one waypoint and one track: %s", out.String())
+	}
+	waypoint := document.Waypoints[0]
+	if waypoint.Lat != 47.6 || waypoint.Lon != -122.3 || waypoint.Name != "Cafe & Co" || waypoint.Type != "cafe" {
+		t.Fatalf("unexpected waypoint: %#v", waypoint)
+	}
+	if waypoint.Link == nil || !strings.HasPrefix(waypoint.Link.Href, "https://www.google.com/maps/search/?") {
+		t.Fatalf("unexpected link: %#v", waypoint.Link)
+	}
+	if len(document.Tracks[0].Segment.Points) != 2 {
+		t.Fatalf("unexpected track: %#v", document.Tracks[0])
+	}
+}
+
+func TestPlacesExportSkipsMissingLocations(t *testing.T) {
+	export := placesExport([]gplace.PlaceSummary{{PlaceID: "nowhere"}, {PlaceID: "abc", Location: &gplace.LatLng{Lat: 1, Lng: 2}}})
+	if len(export.Points) != 1 || export.Points[0].PlaceID != "abc" {
+		t.Fatalf("unexpected points: %#v", export.Points)
+	}
+	if details := detailsExport(gplace.PlaceDetails{PlaceID: "x", GoogleMapsURI: "https://maps.google.com/?cid=1", Location: &gplace.LatLng{}}); details.Points[0].MapsURI != "https://maps.google.com/?cid=1" {
+		t.Fatalf("expected details to keep its maps URI: %#v", details.Points)
+	}
+}
+
+func TestRunRouteGPX(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesComputePath:
+			_, _ = w.Write([]byte("{\"routes\":[{\"polyline\":{\"encodedPolyline\":\"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case placesSearchPath:
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Fuel"},"location":{"latitude":40,"longitude":-120.5}}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"gas",
+		"--from", "A",
+		"--to", "B",
+		"--format", "gpx",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--routes-base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	var document gpxDocument
+	if err := xml.Unmarshal(stdout.Bytes(), &document); err != nil {
+		t.Fatalf("parse gpx: %v (%s)", err, stdout.String())
+	}
+	if len(document.Waypoints) != 1 || document.Waypoints[0].Name != "Fuel" {
+		t.Fatalf("unexpected waypoints: %#v", document.Waypoints)
+	}
+	if len(document.Tracks) != 1 || len(document.Tracks[0].Segment.Points) != 3 {
+		t.Fatalf("unexpected tracks: %#v", document.Tracks)
+	}
+}
@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/qztseng/gplace"
+)
+
+// markdownReviews caps the reviews quoted on a card.
+const markdownReviews = 3
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"|", `\|`,
+)
+
+// markdownCard holds the fields shared by every place card. Details fill in
+// the optional sections; summaries leave them empty.
+type markdownCard struct {
+	name        string
+	address     string
+	placeID     string
+	rating      *float64
+	ratingCount *int
+	priceLevel  *int
+	priceRange  *gplace.PriceRange
+	placeType   string
+	openNow     *bool
+	location    *gplace.LatLng
+	phone       string
+	website     string
+	mapsURI     string
+	route       string
+	serves      []string
+	summaries   []markdownSummary
+	hours       []string
+	reviews     []gplace.Review
+}
+
+type markdownSummary struct {
+	label string
+	text  string
+}
+
+func renderPlacesMarkdown(places []gplace.PlaceSummary) string {
+	cards := make([]markdownCard, 0, len(places))
+	for _, place := range places {
+		cards = append(cards, summaryCard(place))
+	}
+	return joinCards(cards)
+}
+
+func renderDetailsMarkdown(place gplace.PlaceDetails) string {
+	placeType := place.PrimaryTypeDisplayName
+	if placeType == "" {
+		placeType = place.PrimaryType
+	}
+	card := markdownCard{
+		name:        place.Name,
+		address:     place.Address,
+		placeID:     place.PlaceID,
+		rating:      place.Rating,
+		ratingCount: place.UserRatingCount,
+		priceLevel:  place.PriceLevel,
+		priceRange:  place.PriceRange,
+		placeType:   placeType,
+		openNow:     place.OpenNow,
+		location:    place.Location,
+		phone:       place.Phone,
+		website:     place.Website,
+		mapsURI:     place.GoogleMapsURI,
+		serves:      amenityLabels(place),
+		hours:       place.Hours,
+		reviews:     place.Reviews,
+	}
+	for _, summary := range []markdownSummary{
+		{label: "Summary", text: place.EditorialSummary},
+		{label: "AI overview", text: place.GenerativeSummary},
+		{label: "Review summary", text: place.ReviewSummary},
+	} {
+		if strings.TrimSpace(summary.text) != "" {
+			card.summaries = append(card.summaries, summary)
+		}
+	}
+	return card.render()
+}
+
+func renderResolvedMarkdown(locations []gplace.ResolvedLocation) string {
+	cards := make([]markdownCard, 0, len(locations))
+	for _, location := range locations {
+		cards = append(cards, markdownCard{
+			name:      location.Name,
+			address:   location.Address,
+			placeID:   location.PlaceID,
+			placeType: strings.Join(uniqueStrings(location.Types), ", "),
+			location:  location.Location,
+		})
+	}
+	return joinCards(cards)
+}
+
+func renderRouteMarkdown(response gplace.RouteResponse) string {
+	var out bytes.Buffer
+	if route := response.Route; route != nil {
+		out.WriteString("# Route\n\n")
+		out.WriteString(fmt.Sprintf("- **Distance:** %s\n", formatDistance(float64(route.DistanceMeters))))
+		out.WriteString(fmt.Sprintf("- **Duration:** %s\n", formatDuration(route.DurationSeconds)))
+		if len(response.Waypoints) > 0 {
+			out.WriteString(fmt.Sprintf("- **Waypoints searched:** %d\n", len(response.Waypoints)))
+		}
+		if len(response.Places) > 0 {
+			out.WriteString("\n")
+		}
+	}
+	cards := make([]markdownCard, 0, len(response.Places))
+	for _, place := range response.Places {
+		card := summaryCard(place.PlaceSummary)
+		card.route = routePlacePosition(place)
+		cards = append(cards, card)
+	}
+	out.WriteString(joinCards(cards))
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func summaryCard(place gplace.PlaceSummary) markdownCard {
+	return markdownCard{
+		name:        place.Name,
+		address:     place.Address,
+		placeID:     place.PlaceID,
+		rating:      place.Rating,
+		ratingCount: place.UserRatingCount,
+		priceLevel:  place.PriceLevel,
+		placeType:   firstType(place.Types),
+		openNow:     place.OpenNow,
+		location:    place.Location,
+		mapsURI:     placeMapsURL(place.Name, place.PlaceID),
+	}
+}
+
+func joinCards(cards []markdownCard) string {
+	if len(cards) == 0 {
+		return emptyResultsMessage
+	}
+	rendered := make([]string, 0, len(cards))
+	for _, card := range cards {
+		rendered = append(rendered, card.render())
+	}
+	return strings.Join(rendered, "\n\n---\n\n")
+}
+
+func (c markdownCard) render() string {
+	var out bytes.Buffer
+	name := strings.TrimSpace(c.name)
+	if name == "" {
+		name = "(no name)"
+	}
+	out.WriteString("## " + markdownEscaper.Replace(name) + "\n")
+	if c.address != "" {
+		out.WriteString("\n_" + markdownEscaper.Replace(c.address) + "_\n")
+	}
+	out.WriteString("\n")
+
+	if c.rating != nil {
+		rating := fmt.Sprintf("%s %.1f", formatStars(*c.rating), *c.rating)
+		if c.ratingCount != nil {
+			rating += fmt.Sprintf(" (%d reviews)", *c.ratingCount)
+		}
+		writeMarkdownField(&out, "Rating", rating)
+	}
+	price := make([]string, 0, 2)
+	if c.priceLevel != nil && *c.priceLevel > 0 {
+		price = append(price, formatPriceLevel(*c.priceLevel))
+	}
+	if priceRange := formatPriceRange(c.priceRange); priceRange != "" {
+		price = append(price, priceRange)
+	}
+	writeMarkdownField(&out, "Price", strings.Join(price, " · "))
+	writeMarkdownField(&out, "Type", c.placeType)
+	if c.openNow != nil {
+		openNow := "no"
+		if *c.openNow {
+			openNow = "yes"
+		}
+		writeMarkdownField(&out, "Open now", openNow)
+	}
+	writeMarkdownField(&out, "Serves", strings.Join(c.serves, ", "))
+	writeMarkdownField(&out, "Route", c.route)
+	writeMarkdownField(&out, "Phone", c.phone)
+	if c.website != "" {
+		writeMarkdownLink(&out, "Website", c.website)
+	}
+	if c.mapsURI != "" {
+		writeMarkdownLink(&out, "Google Maps", c.mapsURI)
+	}
+	if c.location != nil {
+		writeMarkdownField(&out, "Location", fmt.Sprintf("%.6f, %.6f", c.location.Lat, c.location.Lng))
+	}
+	if c.placeID != "" {
+		out.WriteString("- **Place ID:** `" + c.placeID + "`\n")
+	}
+
+	for _, summary := range c.summaries {
+		out.WriteString("\n### " + summary.label + "\n\n")
+		out.WriteString(markdownEscaper.Replace(strings.TrimSpace(summary.text)) + "\n")
+	}
+
+	if len(c.hours) > 0 {
+		out.WriteString("\n### Hours\n\n")
+		for _, entry := range c.hours {
+			out.WriteString("- " + markdownEscaper.Replace(entry) + "\n")
+		}
+	}
+
+	if len(c.reviews) > 0 {
+		out.WriteString("\n### Top reviews\n")
+		for _, review := range c.reviews[:min(len(c.reviews), markdownReviews)] {
+			writeMarkdownReview(&out, review)
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// writeMarkdownField writes an escaped "- **Label:** value" line, skipping
+// empty values.
+func writeMarkdownField(out *bytes.Buffer, label string, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	out.WriteString("- **" + label + ":** " + markdownEscaper.Replace(value) + "\n")
+}
+
+func writeMarkdownLink(out *bytes.Buffer, label string, uri string) {
+	out.WriteString(fmt.Sprintf("- **%s:** <%s>\n", label, uri))
+}
+
+// writeMarkdownReview quotes a review, with the rating and author as the
+// first line of the quote.
+func writeMarkdownReview(out *bytes.Buffer, review gplace.Review) {
+	header := make([]string, 0, 3)
+	if review.Rating != nil {
+		header = append(header, formatStars(*review.Rating))
+	}
+	if review.Author != nil && strings.TrimSpace(review.Author.DisplayName) != "" {
+		header = append(header, "**"+markdownEscaper.Replace(review.Author.DisplayName)+"**")
+	}
+	if when := strings.TrimSpace(review.RelativePublishTimeDescription); when != "" {
+		header = append(header, "_"+markdownEscaper.Replace(when)+"_")
+	}
+	text := reviewText(review)
+	if len(header) == 0 && text == "" {
+		return
+	}
+
+	out.WriteString("\n")
+	if len(header) > 0 {
+		out.WriteString("> " + strings.Join(header, " · ") + "\n")
+	}
+	if text != "" {
+		if len(header) > 0 {
+			out.WriteString(">\n")
+		}
+		for _, line := range strings.Split(text, "\n") {
+			out.WriteString(strings.TrimRight("> "+markdownEscaper.Replace(line), " ") + "\n")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+func TestRenderDetailsMarkdown(t *testing.T) {
+	rating := 4.4
+	reviewRating := 5.0
+	count := 120
+	level := 2
+	coffee := true
+	place := gplace.PlaceDetails{
+		PlaceID:                "abc",
+		Name:                   "Cafe *Star*",
+		Address:                "1 Main St",
+		Rating:                 &rating,
+		UserRatingCount:        &count,
+		PriceLevel:             &level,
+		PriceRange:             &gplace.PriceRange{StartPrice: &gplace.Money{CurrencyCode: "USD", Units: 10}, EndPrice: &gplace.Money{CurrencyCode: "USD", Units: 20}},
+		PrimaryTypeDisplayName: "Coffee shop",
+		GoogleMapsURI:          "https://maps.google.com/?cid=1",
+		ServesCoffee:           &coffee,
+		ReviewSummary:          "Great espresso.",
+		Hours:                  []string{"Monday: 7:00 AM – 5:00 PM"},
+		Reviews: []gplace.Review{
+			{Rating: &reviewRating, Author: &gplace.AuthorAttribution{DisplayName: "Ann"}, RelativePublishTimeDescription: "a week ago", Text: &gplace.LocalizedText{Text: "Lovely."}},
+			{Text: &gplace.LocalizedText{Text: "Two"}},
+			{Text: &gplace.LocalizedText{Text: "Three"}},
+			{Text: &gplace.LocalizedText{Text: "Four"}},
+		},
+	}
+
+	out := renderDetailsMarkdown(place)
+	for _, want := range []string{
+		"## Cafe \\*Star\\*\n\n_1 Main St_\n",
+		"- **Rating:** ★★★★☆ 4.4 (120 reviews)\n",
+		"- **Price:** $$ · $10–$20\n",
+		"- **Type:** Coffee shop\n",
+		"- **Serves:** Coffee\n",
+		"- **Google Maps:** <https://maps.google.com/?cid=1>\n",
+		"- **Place ID:** `abc`\n",
+		"### Review summary\n\nGreat espresso.\n",
+		"### Hours\n\n- Monday: 7:00 AM – 5:00 PM\n",
+		"> ★★★★★ · **Ann** · _a week ago_\n>\n> Lovely.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Four") {
+		t.Fatalf("expected at most %d reviews:\n%s", markdownReviews, out)
+	}
+}
+
+func TestRenderRouteMarkdown(t *testing.T) {
+	along := 1500.0
+	out := renderRouteMarkdown(gplace.RouteResponse{
+		Route:     &gplace.RouteInfo{DistanceMeters: 12000, DurationSeconds: 900},
+		Waypoints: []gplace.RouteWaypoint{{}, {}},
+		Places: []gplace.RoutePlace{
+			{PlaceSummary: gplace.PlaceSummary{PlaceID: "a", Name: "Fuel"}, DistanceAlongM: &along},
+			{PlaceSummary: gplace.PlaceSummary{PlaceID: "b", Name: "Gas"}},
+		},
+	})
+	for _, want := range []string{
+		"# Route\n\n- **Distance:** 12.0 km\n- **Duration:** 15 min\n- **Waypoints searched:** 2\n\n## Fuel",
+		"- **Route:** 1.5 km along\n",
+		"\n\n---\n\n## Gas",
+		"query_place_id=b",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+	if renderPlacesMarkdown(nil) != emptyResultsMessage {
+		t.Fatalf("expected empty message")
+	}
+}
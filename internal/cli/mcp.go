@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/qztseng/gplace"
+)
+
+// mcpProtocolVersions are the Model Context Protocol revisions the server
+// speaks, newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+const mcpInstructions = "Google Places tools. Use resolve_location to turn a named area into coordinates for location_bias, " +
+	"search_places or nearby_places to find places, then place_details with a place_id for hours, amenities and reviews."
+
+// MCPCmd serves gplace tools to AI agents over the Model Context Protocol.
+type MCPCmd struct{}
+
+// mcpServer answers newline-delimited JSON-RPC messages. Tool calls run
+// concurrently; everything else is answered in order.
+type mcpServer struct {
+	client *gplace.Client
+	log    *log.Logger
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	callsMu sync.Mutex
+	calls   map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type mcpToolInfo struct {
+	Name        string       `json:"name"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description"`
+	InputSchema *jsonSchema  `json:"inputSchema"`
+	Annotations mcpToolHints `json:"annotations"`
+}
+
+type mcpToolHints struct {
+	ReadOnlyHint  bool `json:"readOnlyHint"`
+	OpenWorldHint bool `json:"openWorldHint"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content           []mcpContent `json:"content"`
+	StructuredContent any          `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// Run executes the mcp command.
+func (c *MCPCmd) Run(app *App) error {
+	if app.format != formatText {
+		return unsupportedFormat(app.format, "mcp")
+	}
+	server := newMCPServer(app.client, app.out, app.err)
+	if strings.TrimSpace(app.apiKey) == "" {
+		server.log.Printf("no API key; tool calls will fail until one is configured")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return server.serve(ctx, app.in)
+}
+
+func newMCPServer(client *gplace.Client, out io.Writer, logs io.Writer) *mcpServer {
+	return &mcpServer{
+		client: client,
+		out:    out,
+		log:    log.New(logs, "", log.LstdFlags),
+		calls:  map[string]context.CancelFunc{},
+	}
+}
+
+// serve handles messages from in until it closes or ctx is done, then waits
+// for running tool calls.
+func (s *mcpServer) serve(ctx context.Context, in io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(strings.TrimSpace(string(line))) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("gplace: read mcp input: %w", err)
+		case line := <-lines:
+			s.handle(ctx, line)
+		}
+	}
+}
+
+// handle answers one message. Notifications and responses get no reply.
+func (s *mcpServer) handle(ctx context.Context, line []byte) {
+	var msg rpcMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		s.reply(json.RawMessage("null"), nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+		return
+	}
+	if msg.Method == "" {
+		if msg.ID == nil {
+			s.reply(json.RawMessage("null"), nil, &rpcError{Code: rpcInvalidRequest, Message: "missing method"})
+		}
+		return
+	}
+	if msg.ID == nil {
+		s.notify(msg)
+		return
+	}
+
+	switch msg.Method {
+	case "initialize":
+		s.reply(msg.ID, s.initialize(msg.Params), nil)
+	case "ping":
+		s.reply(msg.ID, struct{}{}, nil)
+	case "tools/list":
+		tools := make([]mcpToolInfo, 0, len(agentTools))
+		for _, tool := range agentTools {
+			tools = append(tools, mcpToolInfo{
+				Name:        tool.name,
+				Title:       tool.title,
+				Description: tool.description,
+				InputSchema: tool.inputSchema(),
+				Annotations: mcpToolHints{ReadOnlyHint: true, OpenWorldHint: true},
+			})
+		}
+		s.reply(msg.ID, map[string]any{"tools": tools}, nil)
+	case "tools/call":
+		s.callTool(ctx, msg)
+	default:
+		s.reply(msg.ID, nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + msg.Method})
+	}
+}
+
+// initialize agrees on the client's protocol version when the server speaks
+// it, and otherwise offers the newest one.
+func (s *mcpServer) initialize(params json.RawMessage) any {
+	var request struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = json.Unmarshal(params, &request)
+	version := mcpProtocolVersions[0]
+	if slices.Contains(mcpProtocolVersions, request.ProtocolVersion) {
+		version = request.ProtocolVersion
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]string{"name": "gplace", "version": Version},
+		"instructions":    mcpInstructions,
+	}
+}
+
+// notify handles a notification; only cancellation needs action.
+func (s *mcpServer) notify(msg rpcMessage) {
+	if msg.Method != "notifications/cancelled" {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(msg.Params, &params) != nil {
+		return
+	}
+	s.callsMu.Lock()
+	cancel := s.calls[string(params.RequestID)]
+	s.callsMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// callTool runs a tool in the background. Failures of the tool itself are
+// reported in the result with isError so the agent can correct its
+// arguments; only an unknown tool is a protocol error.
+func (s *mcpServer) callTool(ctx context.Context, msg rpcMessage) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.reply(msg.ID, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+		return
+	}
+	tool, ok := findAgentTool(params.Name)
+	if !ok {
+		s.reply(msg.ID, nil, &rpcError{Code: rpcInvalidParams, Message: "unknown tool " + params.Name})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	key := string(msg.ID)
+	s.callsMu.Lock()
+	s.calls[key] = cancel
+	s.callsMu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.callsMu.Lock()
+			delete(s.calls, key)
+			s.callsMu.Unlock()
+			cancel()
+		}()
+
+		started := time.Now()
+		response, err := tool.call(ctx, s.client, params.Arguments)
+		if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			// The client cancelled the request and expects no response.
+			s.log.Printf("%s cancelled %s", tool.name, time.Since(started).Round(time.Millisecond))
+			return
+		}
+		s.reply(msg.ID, toolResult(response, err), nil)
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		s.log.Printf("%s %s %s", tool.name, status, time.Since(started).Round(time.Millisecond))
+	}()
+}
+
+// toolResult returns the response as structured content, with the same JSON
+// as text for clients that only read text.
+func toolResult(response any, err error) mcpToolResult {
+	if err == nil {
+		payload, marshalErr := json.Marshal(response)
+		if marshalErr == nil {
+			return mcpToolResult{
+				Content:           []mcpContent{{Type: "text", Text: string(payload)}},
+				StructuredContent: response,
+			}
+		}
+		err = marshalErr
+	}
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+}
+
+func (s *mcpServer) reply(id json.RawMessage, result any, rpcErr *rpcError) {
+	payload, err := json.Marshal(rpcResponse{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+	if err != nil {
+		s.log.Printf("encode response: %v", err)
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := s.out.Write(append(payload, '\n')); err != nil {
+		s.log.Printf("write response: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+// runMCP sends the given messages to a server backed by a fake Places API
+// and returns its responses by id.
+func runMCP(t *testing.T, messages ...string) map[string]map[string]any {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != placesSearchPath {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"not found"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	var out, logs bytes.Buffer
+	client := gplace.NewClient(gplace.Options{APIKey: "test-key", BaseURL: upstream.URL})
+	server := newMCPServer(client, &out, &logs)
+	if err := server.serve(context.Background(), strings.NewReader(strings.Join(messages, "\n")+"\n")); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	responses := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var response map[string]any
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		id, _ := json.Marshal(response["id"])
+		responses[string(id)] = response
+	}
+	return responses
+}
+
+func TestMCPSession(t *testing.T) {
+	responses := runMCP(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search_places","arguments":{"query":"coffee","limit":1}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"search_places","arguments":{"query":"coffee","limit":50}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"search_places","arguments":{"query":"coffee","bogus":1}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 8 {
+		t.Fatalf("expected 8 responses (none for the notification), got %d: %v", len(responses), responses)
+	}
+
+	initialized := responses["1"]["result"].(map[string]any)
+	if initialized["protocolVersion"] != "2025-03-26" {
+		t.Fatalf("expected the client's protocol version, got %v", initialized["protocolVersion"])
+	}
+	if info := initialized["serverInfo"].(map[string]any); info["name"] != "gplace" {
+		t.Fatalf("unexpected server info %v", info)
+	}
+
+	tools := responses["2"]["result"].(map[string]any)["tools"].([]any)
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	if !reflect.DeepEqual(names, []string{"search_places", "nearby_places", "place_details", "autocomplete_places", "resolve_location", "route_search"}) {
+		t.Fatalf("unexpected tools %v", names)
+	}
+
+	result := responses["3"]["result"].(map[string]any)
+	if result["isError"] == true {
+		t.Fatalf("unexpected tool error %v", result)
+	}
+	places := result["structuredContent"].(map[string]any)["results"].([]any)
+	if len(places) != 1 || places[0].(map[string]any)["place_id"] != "abc" {
+		t.Fatalf("unexpected structured content %v", result["structuredContent"])
+	}
+	text := result["content"].([]any)[0].(map[string]any)["text"].(string)
+	if !strings.Contains(text, `"place_id":"abc"`) {
+		t.Fatalf("expected JSON text content, got %q", text)
+	}
+
+	for id, want := range map[string]string{"4": "limit", "5": "bogus"} {
+		result := responses[id]["result"].(map[string]any)
+		text := result["content"].([]any)[0].(map[string]any)["text"].(string)
+		if result["isError"] != true || !strings.Contains(text, want) {
+			t.Fatalf("expected tool error about %s for id %s, got %v", want, id, result)
+		}
+	}
+	for id, code := range map[string]float64{"6": rpcInvalidParams, "7": rpcMethodNotFound, "null": rpcParseError} {
+		rpcErr, ok := responses[id]["error"].(map[string]any)
+		if !ok || rpcErr["code"] != code {
+			t.Fatalf("expected error %v for id %s, got %v", code, id, responses[id])
+		}
+	}
+}
+
+func TestSchemaForRequests(t *testing.T) {
+	search := schemaFor(reflect.TypeFor[gplace.SearchRequest]())
+	if !reflect.DeepEqual(search.Required, []string{"query"}) {
+		t.Fatalf("expected query required, got %v", search.Required)
+	}
+	if *search.AdditionalProperties {
+		t.Fatal("expected objects to reject unknown properties")
+	}
+	prices := search.Properties["filters"].Properties["price_levels"]
+	if prices.Type != "array" || prices.Items.Type != "integer" || len(prices.Items.Enum) != 5 {
+		t.Fatalf("unexpected price_levels schema %+v", prices)
+	}
+	bias := search.Properties["location_bias"]
+	if !reflect.DeepEqual(bias.Required, []string{"lat", "lng", "radius_m"}) || *bias.Properties["lat"].Maximum != 90 {
+		t.Fatalf("unexpected location_bias schema %+v", bias)
+	}
+
+	route := schemaFor(reflect.TypeFor[gplace.RouteRequest]())
+	if !slices.Contains(route.Properties["mode"].Enum, any("TRANSIT")) {
+		t.Fatalf("expected travel modes, got %v", route.Properties["mode"].Enum)
+	}
+	if departure := route.Properties["departure_time"]; departure.Type != "string" || departure.Format != "date-time" {
+		t.Fatalf("unexpected departure_time schema %+v", departure)
+	}
+	if *schemaFor(reflect.TypeFor[gplace.LocationResolveRequest]()).Properties["limit"].Maximum != 10 {
+		t.Fatal("expected the resolve limit override")
+	}
+
+	// Every request field is documented, so agents never see a bare name.
+	for _, tool := range agentTools {
+		assertDocumented(t, tool.name, tool.inputSchema())
+	}
+}
+
+func assertDocumented(t *testing.T, path string, schema *jsonSchema) {
+	t.Helper()
+	for name, property := range schema.Properties {
+		if property.Description == "" {
+			t.Errorf("%s.%s has no description", path, name)
+		}
+		if property.Items != nil {
+			property = property.Items
+		}
+		assertDocumented(t, path+"."+name, property)
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/qztseng/gplace"
+)
+
+// NDJSON record types.
+const (
+	recordPlace      = "place"
+	recordSuggestion = "suggestion"
+	recordRoute      = "route"
+	recordWaypoint   = "waypoint"
+	recordPageToken  = "next_page_token"
+	recordResult     = "result"
+	recordError      = "error"
+)
+
+// ndjsonRecord is one line of --format ndjson output. Consumers switch on
+// Type; Data holds the same object --format json would print for it. Batch
+// records carry the request's Op and input line Index.
+type ndjsonRecord struct {
+	Type          string `json:"type"`
+	Op            string `json:"op,omitempty"`
+	Index         *int   `json:"index,omitempty"`
+	Data          any    `json:"data,omitempty"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Field         string `json:"field,omitempty"`
+	StatusCode    int    `json:"status_code,omitempty"`
+}
+
+// writeRecord writes record as a single compact line.
+func writeRecord(writer io.Writer, record ndjsonRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(payload, '\n'))
+	return err
+}
+
+// writeRecords writes one record of kind per value.
+func writeRecords[T any](writer io.Writer, kind string, values []T) error {
+	for _, value := range values {
+		if err := writeRecord(writer, ndjsonRecord{Type: kind, Data: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePageToken(writer io.Writer, token string) error {
+	if token == "" {
+		return nil
+	}
+	return writeRecord(writer, ndjsonRecord{Type: recordPageToken, NextPageToken: token})
+}
+
+// errorRecord describes a command failure, keeping the field of validation
+// errors and the status of API errors.
+func errorRecord(err error) ndjsonRecord {
+	record := ndjsonRecord{Type: recordError, Error: err.Error()}
+	var validation gplace.ValidationError
+	if errors.As(err, &validation) {
+		record.Field = validation.Field
+	}
+	var apiErr *gplace.APIError
+	if errors.As(err, &apiErr) {
+		record.StatusCode = apiErr.StatusCode
+	}
+	return record
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func readRecords(t *testing.T, reader io.Reader) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid ndjson line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func recordTypes(records []map[string]any) string {
+	types := make([]string, 0, len(records))
+	for _, record := range records {
+		types = append(types, record["type"].(string))
+	}
+	return strings.Join(types, ",")
+}
+
+func TestRunSearchNDJSONPages(t *testing.T) {
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		token, _ := body["pageToken"].(string)
+		tokens = append(tokens, token)
+		switch token {
+		case "":
+			_, _ = w.Write([]byte(`{"places":[{"id":"a"},{"id":"b"}],"nextPageToken":"page2"}`))
+		case "page2":
+			_, _ = w.Write([]byte(`{"places":[{"id":"c"}],"nextPageToken":"page3"}`))
+		default:
+			t.Fatalf("unexpected page token: %s", token)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--pages", "2",
+		"--format", "ndjson",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if strings.Join(tokens, ",") != ",page2" {
+		t.Fatalf("unexpected page requests: %q", tokens)
+	}
+	records := readRecords(t, &stdout)
+	if recordTypes(records) != "place,place,place,next_page_token" {
+		t.Fatalf("unexpected records: %s", stdout.String())
+	}
+	if records[2]["data"].(map[string]any)["place_id"] != "c" || records[3]["next_page_token"] != "page3" {
+		t.Fatalf("unexpected records: %s", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("expected empty stderr, got %s", stderr.String())
+	}
+}
+
+func TestRunSearchPagesJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["pageToken"] == "page2" {
+			_, _ = w.Write([]byte(`{"places":[{"id":"b"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"places":[{"id":"a"}],"nextPageToken":"page2"}`))
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--pages", "3",
+		"--json",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	var places []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &places); err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if len(places) != 2 || stderr.Len() != 0 {
+		t.Fatalf("expected both pages and no token, got %s (stderr=%s)", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunRouteNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesComputePath:
+			_, _ = w.Write([]byte("{\"routes\":[{\"distanceMeters\":1000,\"polyline\":{\"encodedPolyline\":\"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case placesSearchPath:
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Fuel"}}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"route",
+		"gas",
+		"--from", "A",
+		"--to", "B",
+		"--max-waypoints", "2",
+		"--format", "ndjson",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+		"--routes-base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	records := readRecords(t, &stdout)
+	if recordTypes(records) != "route,waypoint,waypoint,place" {
+		t.Fatalf("unexpected records: %s", stdout.String())
+	}
+	if _, ok := records[1]["index"]; !ok {
+		t.Fatalf("expected waypoint index: %v", records[1])
+	}
+}
+
+func TestRunNDJSONError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`denied`))
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--format", "ndjson",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", exitCode)
+	}
+	records := readRecords(t, &stdout)
+	if recordTypes(records) != "error" || records[0]["status_code"] != float64(http.StatusForbidden) {
+		t.Fatalf("unexpected records: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	exitCode = Run([]string{"search", "coffee", "--pages", "0", "--format", "ndjson", "--api-key", "test-key"}, &stdout, &stderr)
+	records = readRecords(t, &stdout)
+	if exitCode != 2 || len(records) != 1 || records[0]["field"] != "pages" {
+		t.Fatalf("unexpected validation record (exit %d): %s", exitCode, stdout.String())
+	}
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/qztseng/gplace"
+)
+
+// Output formats for --format.
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatGeoJSON  = "geojson"
+	formatKML      = "kml"
+	formatGPX      = "gpx"
+	formatCSV      = "csv"
+	formatTSV      = "tsv"
+	formatNDJSON   = "ndjson"
+	formatMarkdown = "markdown"
+	formatTable    = "table"
+)
+
+func outputPlaces(app *App, places []gplace.PlaceSummary) error {
+	switch app.format {
+	case formatGeoJSON:
+		collection, err := placeFeatures(places)
+		if err != nil {
+			return err
+		}
+		return writeJSON(app.out, collection)
+	case formatKML:
+		return writeKML(app.out, placesExport(places))
+	case formatGPX:
+		return writeGPX(app.out, placesExport(places))
+	case formatCSV, formatTSV, formatTable:
+		data, err := newTable(places, app.columns, placeColumns)
+		if err != nil {
+			return err
+		}
+		return writeTable(app, data)
+	case formatNDJSON:
+		return writeRecords(app.out, recordPlace, places)
+	case formatMarkdown:
+		return writeText(app, renderPlacesMarkdown(places))
+	case formatTemplate:
+		return writeTemplate(app, places)
+	default:
+		return writeJSON(app.out, places)
+	}
+}
+
+func outputDetails(app *App, place gplace.PlaceDetails) error {
+	switch app.format {
+	case formatGeoJSON:
+		collection, err := detailsFeatures(place)
+		if err != nil {
+			return err
+		}
+		return writeJSON(app.out, collection)
+	case formatKML:
+		return writeKML(app.out, detailsExport(place))
+	case formatGPX:
+		return writeGPX(app.out, detailsExport(place))
+	case formatCSV, formatTSV, formatTable:
+		data, err := newTable([]gplace.PlaceDetails{place}, app.columns, detailsColumns)
+		if err != nil {
+			return err
+		}
+		return writeTable(app, data)
+	case formatNDJSON:
+		return writeRecord(app.out, ndjsonRecord{Type: recordPlace, Data: place})
+	case formatMarkdown:
+		return writeText(app, renderDetailsMarkdown(place))
+	case formatTemplate:
+		return writeTemplate(app, []gplace.PlaceDetails{place})
+	default:
+		return writeJSON(app.out, place)
+	}
+}
+
+func outputResolved(app *App, locations []gplace.ResolvedLocation) error {
+	switch app.format {
+	case formatGeoJSON:
+		collection, err := resolvedFeatures(locations)
+		if err != nil {
+			return err
+		}
+		return writeJSON(app.out, collection)
+	case formatKML:
+		return writeKML(app.out, resolvedExport(locations))
+	case formatGPX:
+		return writeGPX(app.out, resolvedExport(locations))
+	case formatCSV, formatTSV, formatTable:
+		data, err := newTable(locations, app.columns, resolvedColumns)
+		if err != nil {
+			return err
+		}
+		return writeTable(app, data)
+	case formatNDJSON:
+		return writeRecords(app.out, recordPlace, locations)
+	case formatMarkdown:
+		return writeText(app, renderResolvedMarkdown(locations))
+	case formatTemplate:
+		return writeTemplate(app, locations)
+	default:
+		return writeJSON(app.out, locations)
+	}
+}
+
+func outputRoute(app *App, response gplace.RouteResponse) error {
+	switch app.format {
+	case formatGeoJSON:
+		collection, err := routeFeatures(response)
+		if err != nil {
+			return err
+		}
+		return writeJSON(app.out, collection)
+	case formatKML:
+		return writeKML(app.out, routeExport(response))
+	case formatGPX:
+		return writeGPX(app.out, routeExport(response))
+	case formatCSV, formatTSV, formatTable:
+		data, err := newTable(response.Places, app.columns, routeColumns)
+		if err != nil {
+			return err
+		}
+		return writeTable(app, data)
+	case formatNDJSON:
+		if response.Route != nil {
+			if err := writeRecord(app.out, ndjsonRecord{Type: recordRoute, Data: response.Route}); err != nil {
+				return err
+			}
+		}
+		for i, waypoint := range response.Waypoints {
+			if err := writeRecord(app.out, ndjsonRecord{Type: recordWaypoint, Index: &i, Data: waypoint}); err != nil {
+				return err
+			}
+		}
+		return writeRecords(app.out, recordPlace, response.Places)
+	case formatMarkdown:
+		return writeText(app, renderRouteMarkdown(response))
+	case formatTemplate:
+		// Without a query there are no places; render the route itself.
+		if len(response.Places) == 0 && len(response.Waypoints) == 0 && response.Route != nil {
+			return writeTemplate(app, []gplace.RouteInfo{*response.Route})
+		}
+		return writeTemplate(app, response.Places)
+	default:
+		return writeJSON(app.out, response)
+	}
+}
+
+// outputPageToken reports a pagination token: as a record in NDJSON,
+// otherwise on stderr so it does not corrupt the output.
+func outputPageToken(app *App, token string) error {
+	if app.format == formatNDJSON {
+		return writePageToken(app.out, token)
+	}
+	if token != "" {
+		_, _ = fmt.Fprintln(app.err, "next_page_token:", token)
+	}
+	return nil
+}
+
+func writeTable(app *App, data table) error {
+	switch app.format {
+	case formatTSV:
+		return writeTSV(app.out, data)
+	case formatTable:
+		return writeText(app, renderTable(data, outputWidth(app.out)))
+	default:
+		return writeCSV(app.out, data)
+	}
+}
+
+func writeText(app *App, text string) error {
+	_, err := fmt.Fprintln(app.out, text)
+	return err
+}
+
+// outputWidth is the line width for --format table: the terminal's width
+// when out is one, else $COLUMNS, else 0 for no limit.
+func outputWidth(out io.Writer) int {
+	if file, ok := out.(*os.File); ok {
+		if width, err := terminalWidth(int(file.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 0
+}
+
+func unsupportedFormat(format string, command string) error {
+	return gplace.ValidationError{Field: "format", Message: fmt.Sprintf("%s is not supported for %s", format, command)}
+}
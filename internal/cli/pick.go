@@ -0,0 +1,497 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/qztseng/gplace"
+)
+
+// PickCmd interactively picks a place from autocomplete suggestions.
+type PickCmd struct {
+	Input    string        `arg:"" optional:"" name:"input" help:"Initial input text."`
+	Limit    int           `help:"Max suggestions (1-20)." default:"5"`
+	Language string        `help:"BCP-47 language code (e.g. en, en-US)."`
+	Region   string        `help:"CLDR region code (e.g. US, DE)."`
+	Lat      *float64      `help:"Latitude for location bias."`
+	Lng      *float64      `help:"Longitude for location bias."`
+	RadiusM  *float64      `help:"Radius in meters for location bias."`
+	Reviews  bool          `help:"Include reviews in the details response."`
+	Debounce time.Duration `help:"Delay after the last keystroke before querying." default:"200ms"`
+}
+
+var errPickCancelled = errors.New("gplace: pick cancelled")
+
+// Run executes the pick command.
+func (c *PickCmd) Run(app *App) error {
+	request := gplace.AutocompleteRequest{
+		Limit:        c.Limit,
+		SessionToken: newSessionToken(),
+		Language:     c.Language,
+		Region:       c.Region,
+	}
+	if c.Lat != nil || c.Lng != nil || c.RadiusM != nil {
+		if c.Lat == nil || c.Lng == nil || c.RadiusM == nil {
+			return gplace.ValidationError{Field: "location_bias", Message: "lat, lng, radius required"}
+		}
+		request.LocationBias = &gplace.LocationBias{
+			Lat:     *c.Lat,
+			Lng:     *c.Lng,
+			RadiusM: *c.RadiusM,
+		}
+	}
+
+	restore, err := makeRaw(int(app.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("gplace: pick requires an interactive terminal: %w", err)
+	}
+	defer func() { _ = restore() }()
+	terminal := &pickTerminal{file: app.in}
+
+	p := &picker{
+		client:   app.client,
+		request:  request,
+		debounce: c.Debounce,
+		ui:       app.err,
+		color:    app.color,
+		width: func() int {
+			width, _ := terminalWidth(int(app.in.Fd()))
+			return width
+		},
+		query: []rune(c.Input),
+	}
+	choice, err := p.run(context.Background(), terminal)
+	// Stop the key reader and leave raw mode before printing the details.
+	terminal.close()
+	_ = restore()
+	if err != nil {
+		return err
+	}
+
+	details := &DetailsCmd{
+		PlaceID:      choice.PlaceID,
+		Language:     c.Language,
+		Region:       c.Region,
+		Reviews:      c.Reviews,
+		SessionToken: request.SessionToken,
+	}
+	return details.Run(app)
+}
+
+// pickTerminal reads from a raw terminal whose reads time out (see makeRaw),
+// so the picker's key reader stops once close is called instead of staying
+// blocked and taking input meant for whatever reads the terminal next.
+type pickTerminal struct {
+	file    *os.File
+	mu      sync.Mutex
+	stopped bool
+}
+
+func (t *pickTerminal) Read(p []byte) (int, error) {
+	for {
+		t.mu.Lock()
+		if t.stopped {
+			t.mu.Unlock()
+			return 0, io.EOF
+		}
+		n, err := t.file.Read(p)
+		t.mu.Unlock()
+		// A read that timed out returns no bytes, which os.File reports as EOF.
+		if n == 0 && errors.Is(err, io.EOF) {
+			continue
+		}
+		return n, err
+	}
+}
+
+// close waits for an in-flight read and makes later reads return io.EOF.
+func (t *pickTerminal) close() {
+	t.mu.Lock()
+	t.stopped = true
+	t.mu.Unlock()
+}
+
+// picker holds the interactive autocomplete state.
+type picker struct {
+	client   *gplace.Client
+	request  gplace.AutocompleteRequest
+	debounce time.Duration
+	ui       io.Writer
+	color    Color
+	// width returns the terminal width in columns, 0 when unknown.
+	width func() int
+
+	query       []rune
+	suggestions []gplace.AutocompleteSuggestion
+	selected    int
+	status      string
+}
+
+type pickResult struct {
+	seq      int
+	response gplace.AutocompleteResponse
+	err      error
+}
+
+// run reads keys from input until a place suggestion is chosen.
+func (p *picker) run(ctx context.Context, input io.Reader) (gplace.AutocompleteSuggestion, error) {
+	keys := make(chan []pickKey)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go readPickKeys(input, keys, readErr, done)
+
+	results := make(chan pickResult)
+	var timer <-chan time.Time
+	cancel := func() {}
+	defer func() { cancel() }()
+	defer p.clear()
+
+	// seq identifies the current query; results for older queries are stale.
+	seq := 0
+	pending := false
+	submit := false
+	changed := func() {
+		seq++
+		p.selected = 0
+		if len(p.query) == 0 {
+			timer = nil
+			pending = false
+			p.suggestions = nil
+			p.status = ""
+			return
+		}
+		timer = time.After(p.debounce)
+		pending = true
+	}
+	// choose returns true once a place is picked; query suggestions refine the input.
+	choose := func() bool {
+		submit = false
+		if len(p.suggestions) == 0 {
+			return false
+		}
+		suggestion := p.suggestions[p.selected]
+		if suggestion.Kind == "place" && suggestion.PlaceID != "" {
+			return true
+		}
+		p.query = []rune(suggestion.Text)
+		changed()
+		return false
+	}
+
+	if len(p.query) > 0 {
+		changed()
+	}
+	p.draw(pending)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return gplace.AutocompleteSuggestion{}, ctx.Err()
+		case err := <-readErr:
+			readErr = nil
+			if !errors.Is(err, io.EOF) {
+				return gplace.AutocompleteSuggestion{}, err
+			}
+			// Input closed: finish a pending Enter, otherwise give up.
+			if !submit {
+				return gplace.AutocompleteSuggestion{}, errPickCancelled
+			}
+		case batch := <-keys:
+			for _, key := range batch {
+				switch key.kind {
+				case pickKeyCancel:
+					return gplace.AutocompleteSuggestion{}, errPickCancelled
+				case pickKeyEnter:
+					if pending {
+						submit = true
+					} else if choose() {
+						return p.suggestions[p.selected], nil
+					}
+				case pickKeyUp:
+					if p.selected > 0 {
+						p.selected--
+					}
+				case pickKeyDown:
+					if p.selected < len(p.suggestions)-1 {
+						p.selected++
+					}
+				case pickKeyBackspace:
+					if len(p.query) > 0 {
+						p.query = p.query[:len(p.query)-1]
+						changed()
+					}
+				case pickKeyClear:
+					p.query = nil
+					changed()
+				case pickKeyRune:
+					p.query = append(p.query, key.r)
+					changed()
+				}
+			}
+			p.draw(pending)
+		case <-timer:
+			timer = nil
+			cancel()
+			searchCtx, searchCancel := context.WithCancel(ctx)
+			cancel = searchCancel
+			request := p.request
+			request.Input = string(p.query)
+			go func(seq int) {
+				response, err := p.client.Autocomplete(searchCtx, request)
+				select {
+				case results <- pickResult{seq: seq, response: response, err: err}:
+				case <-searchCtx.Done():
+				}
+			}(seq)
+		case result := <-results:
+			if result.seq != seq {
+				continue
+			}
+			pending = false
+			p.suggestions = result.response.Suggestions
+			p.status = ""
+			if result.err != nil {
+				p.suggestions = nil
+				p.status = result.err.Error()
+			} else if len(p.suggestions) == 0 {
+				p.status = emptyResultsMessage
+			}
+			if p.selected >= len(p.suggestions) {
+				p.selected = 0
+			}
+			if submit && choose() {
+				return p.suggestions[p.selected], nil
+			}
+			p.draw(pending)
+		}
+	}
+}
+
+// draw repaints the prompt and suggestions below it, leaving the cursor on
+// the prompt line so the next repaint only needs to clear downwards. Lines
+// are cut to the terminal width so each takes one row, and the cursor column
+// counts wide (CJK, emoji) characters as two.
+func (p *picker) draw(pending bool) {
+	width := 0
+	if p.width != nil {
+		width = p.width()
+	}
+	// Keep the last column free: writing into it wraps on some terminals.
+	fit := func(line string, plain string, used int) string {
+		if width <= used+1 || displayWidth(plain) < width-used {
+			return line
+		}
+		return truncateWidth(plain, width-used-1)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("\r\x1b[J")
+	out.WriteString(p.color.Bold("> "))
+	query := string(p.query)
+	if width > 3 {
+		// Show the end of a long query, where the typing happens.
+		query = tailWidth(query, width-3)
+	}
+	out.WriteString(query)
+
+	lines := 0
+	for i, suggestion := range p.suggestions {
+		marker := "  "
+		if i == p.selected {
+			marker = p.color.Cyan("› ")
+		}
+		out.WriteString("\n")
+		out.WriteString(marker)
+		title := formatAutocompleteTitle(p.color, suggestion)
+		out.WriteString(fit(title, formatAutocompleteTitle(NewColor(false), suggestion), 2))
+		lines++
+	}
+	status := p.status
+	if pending {
+		status = "Searching..."
+	}
+	if status != "" {
+		out.WriteString("\n")
+		out.WriteString(p.color.Dim(fit(status, status, 0)))
+		lines++
+	}
+
+	if lines > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", lines)
+	}
+	fmt.Fprintf(&out, "\r\x1b[%dC", 2+displayWidth(query))
+	_, _ = p.ui.Write(out.Bytes())
+}
+
+func (p *picker) clear() {
+	_, _ = io.WriteString(p.ui, "\r\x1b[J")
+}
+
+type pickKeyKind int
+
+const (
+	pickKeyRune pickKeyKind = iota
+	pickKeyEnter
+	pickKeyBackspace
+	pickKeyClear
+	pickKeyUp
+	pickKeyDown
+	pickKeyCancel
+)
+
+type pickKey struct {
+	kind pickKeyKind
+	r    rune
+}
+
+func readPickKeys(input io.Reader, keys chan<- []pickKey, readErr chan<- error, done <-chan struct{}) {
+	buf := make([]byte, 256)
+	for {
+		n, err := input.Read(buf)
+		if n > 0 {
+			if batch := decodePickKeys(buf[:n]); len(batch) > 0 {
+				select {
+				case keys <- batch:
+				case <-done:
+					return
+				}
+			}
+		}
+		if err != nil {
+			readErr <- err
+			return
+		}
+	}
+}
+
+// decodePickKeys maps raw terminal bytes to picker keys. Escape sequences
+// arrive in a single read, so a lone ESC byte means the Escape key.
+func decodePickKeys(data []byte) []pickKey {
+	var keys []pickKey
+	for len(data) > 0 {
+		switch b := data[0]; {
+		case b == 0x1b && len(data) >= 3 && (data[1] == '[' || data[1] == 'O'):
+			switch data[2] {
+			case 'A':
+				keys = append(keys, pickKey{kind: pickKeyUp})
+			case 'B':
+				keys = append(keys, pickKey{kind: pickKeyDown})
+			}
+			data = data[3:]
+			continue
+		case b == 0x1b || b == 0x03 || b == 0x04:
+			keys = append(keys, pickKey{kind: pickKeyCancel})
+		case b == '\r' || b == '\n':
+			keys = append(keys, pickKey{kind: pickKeyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, pickKey{kind: pickKeyBackspace})
+		case b == 0x15:
+			keys = append(keys, pickKey{kind: pickKeyClear})
+		case b == 0x10:
+			keys = append(keys, pickKey{kind: pickKeyUp})
+		case b == 0x0e || b == '\t':
+			keys = append(keys, pickKey{kind: pickKeyDown})
+		case b < 0x20:
+			// Ignore other control characters.
+		default:
+			r, size := utf8.DecodeRune(data)
+			if r != utf8.RuneError {
+				keys = append(keys, pickKey{kind: pickKeyRune, r: r})
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// truncateWidth cuts s to at most width terminal columns, marking the cut
+// with "…".
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	var out strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		out.WriteRune(r)
+		used += w
+	}
+	return out.String() + "…"
+}
+
+// tailWidth keeps the end of s that fits in width terminal columns.
+func tailWidth(s string, width int) string {
+	runes := []rune(s)
+	used := 0
+	start := len(runes)
+	for start > 0 && used+runeWidth(runes[start-1]) <= width {
+		start--
+		used += runeWidth(runes[start])
+	}
+	return string(runes[start:])
+}
+
+// displayWidth is the number of terminal columns s takes.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth is the number of terminal columns r takes: 0 for combining marks
+// and format characters, 2 for East Asian wide and fullwidth characters and
+// emoji, otherwise 1.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r < 0x1100:
+		return 1
+	case r <= 0x115f, // Hangul Jamo initials
+		r >= 0x2e80 && r <= 0x303e, // CJK radicals, punctuation
+		r >= 0x3041 && r <= 0x33ff, // Kana, CJK symbols
+		r >= 0x3400 && r <= 0x4dbf, // CJK extension A
+		r >= 0x4e00 && r <= 0x9fff, // CJK unified ideographs
+		r >= 0xa000 && r <= 0xa4cf, // Yi
+		r >= 0xac00 && r <= 0xd7a3, // Hangul syllables
+		r >= 0xf900 && r <= 0xfaff, // CJK compatibility ideographs
+		r >= 0xfe30 && r <= 0xfe4f, // CJK compatibility forms
+		r >= 0xff00 && r <= 0xff60, // Fullwidth forms
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f, // Emoji: pictographs, emoticons
+		r >= 0x1f680 && r <= 0x1f6ff, // Transport and map symbols
+		r >= 0x1f900 && r <= 0x1f9ff, // Supplemental symbols and pictographs
+		r >= 0x20000 && r <= 0x3fffd: // CJK extensions B and later
+		return 2
+	}
+	return 1
+}
+
+// newSessionToken returns a random UUIDv4, the format Places expects for
+// autocomplete session tokens.
+func newSessionToken() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
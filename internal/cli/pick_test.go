@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qztseng/gplace"
+)
+
+const pickSuggestionsPayload = `{
+  "suggestions": [
+    {"placePrediction": {"placeId": "place-1", "structuredFormat": {"mainText": {"text": "Cafe One"}}}},
+    {"placePrediction": {"placeId": "place-2", "structuredFormat": {"mainText": {"text": "Cafe Two"}}}}
+  ]
+}`
+
+func TestDecodePickKeys(t *testing.T) {
+	keys := decodePickKeys([]byte("a\x1b[B\x1bOA\x7f\r\x15é\x03\x1b"))
+	want := []pickKeyKind{
+		pickKeyRune, pickKeyDown, pickKeyUp, pickKeyBackspace, pickKeyEnter,
+		pickKeyClear, pickKeyRune, pickKeyCancel, pickKeyCancel,
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("unexpected keys: %#v", keys)
+	}
+	for i, kind := range want {
+		if keys[i].kind != kind {
+			t.Fatalf("key %d: expected %d, got %d", i, kind, keys[i].kind)
+		}
+	}
+	if keys[0].r != 'a' || keys[6].r != 'é' {
+		t.Fatalf("unexpected runes: %#v", keys)
+	}
+}
+
+func TestPickerEnterWaitsForResults(t *testing.T) {
+	var mu sync.Mutex
+	var inputs []string
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		inputs = append(inputs, payload["input"].(string))
+		tokens = append(tokens, payload["sessionToken"].(string))
+		mu.Unlock()
+		_, _ = w.Write([]byte(pickSuggestionsPayload))
+	}))
+	defer server.Close()
+
+	p := newTestPicker(server.URL, io.Discard)
+	choice, err := p.run(context.Background(), strings.NewReader("caf\r"))
+	if err != nil {
+		t.Fatalf("pick error: %v", err)
+	}
+	if choice.PlaceID != "place-1" {
+		t.Fatalf("unexpected choice: %#v", choice)
+	}
+	// Debounce collapses the typed characters into one request.
+	if len(inputs) != 1 || inputs[0] != "caf" {
+		t.Fatalf("unexpected autocomplete inputs: %#v", inputs)
+	}
+	if tokens[0] != "session" {
+		t.Fatalf("unexpected session token: %#v", tokens)
+	}
+}
+
+func TestPickerArrowSelection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(pickSuggestionsPayload))
+	}))
+	defer server.Close()
+
+	ui := &signalWriter{match: "Cafe Two", ready: make(chan struct{})}
+	input := &gatedReader{chunks: [][]byte{[]byte("caf"), []byte("\x1b[B\r")}, gate: ui.ready}
+	p := newTestPicker(server.URL, ui)
+	choice, err := p.run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("pick error: %v", err)
+	}
+	if choice.PlaceID != "place-2" {
+		t.Fatalf("unexpected choice: %#v", choice)
+	}
+}
+
+func TestPickerCancel(t *testing.T) {
+	p := newTestPicker("http://127.0.0.1:0", io.Discard)
+	_, err := p.run(context.Background(), strings.NewReader("ca\x03"))
+	if !errors.Is(err, errPickCancelled) {
+		t.Fatalf("expected cancel error, got %v", err)
+	}
+
+	_, err = p.run(context.Background(), strings.NewReader(""))
+	if !errors.Is(err, errPickCancelled) {
+		t.Fatalf("expected cancel on EOF, got %v", err)
+	}
+}
+
+func TestPickTerminalClose(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer func() { _ = reader.Close(); _ = writer.Close() }()
+	terminal := &pickTerminal{file: reader}
+
+	_, _ = writer.WriteString("a")
+	buf := make([]byte, 8)
+	if n, err := terminal.Read(buf); err != nil || string(buf[:n]) != "a" {
+		t.Fatalf("unexpected read: %q %v", buf[:n], err)
+	}
+	// After close no more input is taken from the terminal.
+	terminal.close()
+	_, _ = writer.WriteString("b")
+	if _, err := terminal.Read(buf); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF after close, got %v", err)
+	}
+	if n, _ := reader.Read(buf); string(buf[:n]) != "b" {
+		t.Fatalf("expected input left for the next reader, got %q", buf[:n])
+	}
+}
+
+func TestPickerDraw(t *testing.T) {
+	var ui bytes.Buffer
+	p := newTestPicker("", &ui)
+	p.query = []rune("caf")
+	p.suggestions = []gplace.AutocompleteSuggestion{{Kind: "place", MainText: "Cafe One"}, {Kind: "place", MainText: "Cafe Two"}}
+	p.selected = 1
+	p.draw(false)
+
+	output := ui.String()
+	if !strings.Contains(output, "> caf") || !strings.Contains(output, "› Cafe Two") {
+		t.Fatalf("unexpected picker output: %q", output)
+	}
+	if !strings.HasSuffix(output, "\x1b[2A\r\x1b[5C") {
+		t.Fatalf("expected cursor back on prompt line: %q", output)
+	}
+}
+
+func TestPickerDrawWideText(t *testing.T) {
+	var ui bytes.Buffer
+	p := newTestPicker("", &ui)
+	p.width = func() int { return 20 }
+	p.query = []rune("東京")
+	p.suggestions = []gplace.AutocompleteSuggestion{{Kind: "place", MainText: "東京駅", SecondaryText: "日本、東京都千代田区丸の内"}}
+	p.draw(false)
+
+	output := ui.String()
+	if !strings.Contains(output, "\n› 東京駅 — 日本、…\x1b[1A") {
+		t.Fatalf("expected suggestion cut to the terminal width: %q", output)
+	}
+	if !strings.HasSuffix(output, "\r\x1b[6C") {
+		t.Fatalf("expected cursor after two wide characters: %q", output)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	cases := map[string]int{"cafe": 4, "café": 4, "cafe\u0301": 4, "ラーメン": 8, "🍜 ramen": 8, "🚉": 2}
+	for value, want := range cases {
+		if got := displayWidth(value); got != want {
+			t.Errorf("displayWidth(%q) = %d, want %d", value, got, want)
+		}
+	}
+	if got := tailWidth("abc東京", 5); got != "c東京" {
+		t.Errorf("unexpected tail: %q", got)
+	}
+}
+
+func TestNewSessionToken(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	token := newSessionToken()
+	if !pattern.MatchString(token) {
+		t.Fatalf("unexpected session token: %s", token)
+	}
+	if token == newSessionToken() {
+		t.Fatalf("expected unique session tokens")
+	}
+}
+
+func TestRunPickRequiresTerminal(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	stdin, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer func() { _ = stdin.Close() }()
+	defer func() { _ = writer.Close() }()
+
+	exitCode := run([]string{"pick", "--api-key", "test-key"}, stdin, &stdout, &stderr)
+	if exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "interactive terminal") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func newTestPicker(baseURL string, ui io.Writer) *picker {
+	return &picker{
+		client:   gplace.NewClient(gplace.Options{APIKey: "test-key", BaseURL: baseURL}),
+		request:  gplace.AutocompleteRequest{Limit: 5, SessionToken: "session"},
+		debounce: time.Millisecond,
+		ui:       ui,
+		color:    NewColor(false),
+	}
+}
+
+// gatedReader returns its first chunk immediately and the rest after gate closes.
+type gatedReader struct {
+	chunks [][]byte
+	gate   <-chan struct{}
+	index  int
+}
+
+func (r *gatedReader) Read(p []byte) (int, error) {
+	if r.index >= len(r.chunks) {
+		return 0, io.EOF
+	}
+	if r.index > 0 {
+		<-r.gate
+	}
+	n := copy(p, r.chunks[r.index])
+	r.index++
+	return n, nil
+}
+
+// signalWriter closes ready once the written output contains match.
+type signalWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	match string
+	ready chan struct{}
+	once  sync.Once
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	if strings.Contains(w.buf.String(), w.match) {
+		w.once.Do(func() { close(w.ready) })
+	}
+	return len(p), nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/qztseng/gplace"
+)
+
+// TypesCmd lists and searches the place type catalog.
+type TypesCmd struct {
+	Category string `help:"Only list types in this category (e.g. food, lodging)."`
+	Search   string `help:"Only list types whose name contains every word (e.g. ramen)."`
+}
+
+func (*TypesCmd) offline() bool { return true }
+
+// Run executes the types command.
+func (c *TypesCmd) Run(app *App) error {
+	if app.format != formatText && app.format != formatJSON {
+		return unsupportedFormat(app.format, "types")
+	}
+	category := strings.ToLower(strings.TrimSpace(c.Category))
+	if categories := gplace.PlaceTypeCategories(); category != "" && !slices.Contains(categories, category) {
+		return gplace.ValidationError{
+			Field:   "category",
+			Message: fmt.Sprintf("unknown category %q; choose from %s", c.Category, strings.Join(categories, ", ")),
+		}
+	}
+	types := gplace.FindPlaceTypes(c.Search, category)
+	if app.format == formatJSON {
+		if types == nil {
+			types = []gplace.PlaceType{}
+		}
+		return writeJSON(app.out, types)
+	}
+	_, err := fmt.Fprintln(app.out, renderPlaceTypes(app.color, types))
+	return err
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+func TestRunTypes(t *testing.T) {
+	t.Setenv("GOOGLE_PLACES_API_KEY", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"types", "--category", "food", "--search", "ramen", "--json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	var types []gplace.PlaceType
+	if err := json.Unmarshal(stdout.Bytes(), &types); err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	if len(types) != 1 || types[0].Name != "ramen_restaurant" || types[0].Category != "food" {
+		t.Fatalf("unexpected types %+v", types)
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"types", "--category", "geographical_areas", "--search", "administrative area level", "--no-color"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if want := "geographical_areas\n  administrative_area_level_1\n  administrative_area_level_2\n  administrative_area_level_3 (responses only)\n"; !strings.HasPrefix(stdout.String(), want) {
+		t.Fatalf("unexpected text output %q", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := Run([]string{"types", "--category", "fod"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "food") {
+		t.Fatalf("expected exit code 2 listing categories, got %d (stderr=%s)", code, stderr.String())
+	}
+}
+
+func TestRunSearchRejectsUnknownType(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"search", "noodles", "--type", "ramen", "--api-key", "test-key", "--base-url", "http://127.0.0.1:0"}, &stdout, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), "did you mean ramen_restaurant?") {
+		t.Fatalf("expected exit code 2 with a suggestion, got %d (stderr=%s)", code, stderr.String())
+	}
+}
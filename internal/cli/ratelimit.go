@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket per caller: each caller may make burst
+// requests at once, refilled at the configured rate.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter allows perMinute requests per caller; it returns nil, which
+// allows everything, when perMinute is 0.
+func newRateLimiter(perMinute float64, burst int) *rateLimiter {
+	if perMinute == 0 {
+		return nil
+	}
+	return &rateLimiter{
+		perSecond: perMinute / 60,
+		burst:     float64(burst),
+		buckets:   map[string]*tokenBucket{},
+		now:       time.Now,
+	}
+}
+
+// allow takes a token from caller's bucket. When the bucket is empty it
+// reports how long until the next token.
+func (l *rateLimiter) allow(caller string) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.buckets[caller]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[caller] = bucket
+	}
+	bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.perSecond)
+	bucket.updated = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - bucket.tokens) / l.perSecond * float64(time.Second)), false
+}
@@ -47,8 +47,7 @@ func renderAutocomplete(color Color, response gplace.AutocompleteResponse) strin
 	out.WriteString("\n")
 
 	for i, suggestion := range response.Suggestions {
-		title := formatTitle(color, autocompleteTitle(suggestion), autocompleteSubtitle(suggestion))
-		out.WriteString(fmt.Sprintf("%d. %s\n", i+1, title))
+		out.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatAutocompleteTitle(color, suggestion)))
 		writeAutocompleteSuggestion(&out, color, suggestion)
 		if i < count-1 {
 			out.WriteString("\n")
@@ -111,19 +110,97 @@ func renderResolve(color Color, response gplace.LocationResolveResponse) string
 	return out.String()
 }
 
-func renderRoute(color Color, response gplace.RouteResponse) string {
+func renderDedupe(color Color, records []dedupeRecord, total int) string {
 	var out bytes.Buffer
-	count := len(response.Waypoints)
+	count := len(records)
 	if count == 0 {
 		return emptyResultsMessage
 	}
-	out.WriteString(color.Bold(fmt.Sprintf("Route waypoints (%d)", count)))
+	out.WriteString(color.Bold(fmt.Sprintf("Places (%d from %d records)", count, total)))
 	out.WriteString("\n")
 
+	for i, record := range records {
+		out.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatTitle(color, record.Name, record.Address)))
+		writePlaceSummary(&out, color, record.PlaceSummary)
+		if len(record.PlaceIDs) > 1 {
+			writeLine(&out, color, "Merged IDs", strings.Join(record.PlaceIDs[1:], ", "))
+		}
+		writeLine(&out, color, "Sources", strings.Join(record.Sources, ", "))
+		if i < count-1 {
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// renderParsedQuery shows the search an ask query maps to and the phrase
+// behind each field.
+func renderParsedQuery(color Color, parsed gplace.ParsedQuery) string {
+	var out bytes.Buffer
+	writeLine(&out, color, "Query", parsed.Request.Query)
+	writeLine(&out, color, "Near", parsed.Near)
+	if len(parsed.Matches) > 0 {
+		out.WriteString(color.Dim("Matches:"))
+		out.WriteString("\n")
+		for _, match := range parsed.Matches {
+			out.WriteString(fmt.Sprintf("  %q → %s = %s\n", match.Text, match.Field, match.Value))
+		}
+	}
+	if out.Len() == 0 {
+		return emptyResultsMessage
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// renderPlaceTypes lists types under their category, noting those that
+// cannot be used in search or nearby filters.
+func renderPlaceTypes(color Color, types []gplace.PlaceType) string {
+	if len(types) == 0 {
+		return "No place types."
+	}
+	var out bytes.Buffer
+	for i, placeType := range types {
+		if i == 0 || types[i-1].Category != placeType.Category {
+			if i > 0 {
+				out.WriteString("\n")
+			}
+			out.WriteString(color.Bold(placeType.Category))
+			out.WriteString("\n")
+		}
+		out.WriteString("  " + placeType.Name)
+		if placeType.Usage == gplace.PlaceTypeUsageResponse {
+			out.WriteString(" " + color.Dim("(responses only)"))
+		}
+		out.WriteString("\n")
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// renderRoute lists each waypoint's results. With deduplicated places, or
+// when places is set because detours, a detour filter or a sort order was
+// requested, it lists waypoints briefly and shows each place once.
+func renderRoute(color Color, response gplace.RouteResponse, places bool) string {
+	var out bytes.Buffer
+	count := len(response.Waypoints)
+	if count == 0 && len(response.Places) == 0 {
+		return emptyResultsMessage
+	}
+	if count > 0 {
+		out.WriteString(color.Bold(fmt.Sprintf("Route waypoints (%d)", count)))
+		out.WriteString("\n")
+	}
+
+	compact := places || len(response.Places) > 0
 	for i, waypoint := range response.Waypoints {
 		out.WriteString(color.Bold(fmt.Sprintf("Waypoint %d", i+1)))
 		out.WriteString(" ")
 		out.WriteString(color.Dim(fmt.Sprintf("(%.6f, %.6f)", waypoint.Location.Lat, waypoint.Location.Lng)))
+		if compact {
+			out.WriteString(" ")
+			out.WriteString(color.Dim(fmt.Sprintf("· %d results", len(waypoint.Results))))
+			out.WriteString("\n")
+			continue
+		}
 		out.WriteString("\n")
 
 		if len(waypoint.Results) == 0 {
@@ -144,9 +221,98 @@ func renderRoute(color Color, response gplace.RouteResponse) string {
 		}
 	}
 
+	if compact {
+		if count > 0 {
+			out.WriteString("\n")
+		}
+		if len(response.Places) == 0 {
+			// The detour filter dropped every place.
+			out.WriteString(emptyResultsMessage)
+			return out.String()
+		}
+		out.WriteString(color.Bold(fmt.Sprintf("Places along route (%d)", len(response.Places))))
+		out.WriteString("\n")
+		for i, place := range response.Places {
+			out.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatTitle(color, place.Name, place.Address)))
+			writePlaceSummary(&out, color, place.PlaceSummary)
+			writeLine(&out, color, "Route", routePlacePosition(place))
+			if i < len(response.Places)-1 {
+				out.WriteString("\n")
+			}
+		}
+	}
+
 	return out.String()
 }
 
+func renderRouteSummary(color Color, route gplace.RouteInfo) string {
+	var out bytes.Buffer
+	out.WriteString(color.Bold("Route"))
+	out.WriteString("\n")
+	writeLine(&out, color, "Distance", formatDistance(float64(route.DistanceMeters)))
+	writeLine(&out, color, "Duration", formatDuration(route.DurationSeconds))
+	writeLine(&out, color, "Polyline", fmt.Sprintf("%d points", len(route.Polyline)))
+	// A single leg repeats the totals.
+	if len(route.Legs) > 1 {
+		for i, leg := range route.Legs {
+			value := formatDistance(float64(leg.DistanceMeters)) + " · " + formatDuration(leg.DurationSeconds)
+			writeLine(&out, color, fmt.Sprintf("Leg %d", i+1), value)
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func routePlacePosition(place gplace.RoutePlace) string {
+	parts := make([]string, 0, 4)
+	if place.WaypointIndex != nil {
+		parts = append(parts, fmt.Sprintf("near waypoint %d", *place.WaypointIndex+1))
+	}
+	if place.DistanceAlongM != nil {
+		parts = append(parts, formatDistance(*place.DistanceAlongM)+" along")
+	}
+	if place.DistanceFromRouteM != nil {
+		parts = append(parts, formatDistance(*place.DistanceFromRouteM)+" off route")
+	}
+	if detour := formatDetour(place.DetourSeconds, place.DetourMeters); detour != "" {
+		parts = append(parts, detour)
+	}
+	return strings.Join(parts, " · ")
+}
+
+func formatDetour(seconds *int, meters *int) string {
+	parts := make([]string, 0, 2)
+	if seconds != nil {
+		parts = append(parts, fmt.Sprintf("+%d min", (*seconds+30)/60))
+	}
+	if meters != nil {
+		parts = append(parts, "+"+formatDistance(float64(*meters)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, " ") + " detour"
+}
+
+// formatDuration renders seconds as "45 s", "12 min" or "1 h 5 min".
+func formatDuration(seconds int) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%d s", seconds)
+	}
+	minutes := (seconds + 30) / 60
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+}
+
+// formatDistance renders meters as "850 m" or "12.3 km".
+func formatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
 func formatTitle(color Color, name string, address string) string {
 	display := strings.TrimSpace(name)
 	if display == "" {
@@ -160,21 +326,72 @@ func formatTitle(color Color, name string, address string) string {
 
 const emptyResultsMessage = "No results."
 
-func autocompleteTitle(suggestion gplace.AutocompleteSuggestion) string {
+func formatAutocompleteTitle(color Color, suggestion gplace.AutocompleteSuggestion) string {
+	title, titleMatches := autocompleteTitle(suggestion)
+	subtitle, subtitleMatches := autocompleteSubtitle(suggestion)
+	display := strings.TrimSpace(title)
+	if display == "" {
+		display = color.Cyan("(no name)")
+	} else {
+		// Bold the matched input inside the usual cyan title.
+		display = highlightMatches(title, titleMatches, color.Cyan, func(value string) string {
+			return color.Bold(color.Cyan(value))
+		})
+	}
+	if subtitle == "" {
+		return display
+	}
+	plain := func(value string) string { return value }
+	return display + " — " + highlightMatches(subtitle, subtitleMatches, plain, color.Bold)
+}
+
+func autocompleteTitle(suggestion gplace.AutocompleteSuggestion) (string, []gplace.TextMatch) {
 	if strings.TrimSpace(suggestion.MainText) != "" {
-		return suggestion.MainText
+		return suggestion.MainText, suggestion.MainTextMatches
 	}
-	return suggestion.Text
+	return suggestion.Text, suggestion.TextMatches
 }
 
-func autocompleteSubtitle(suggestion gplace.AutocompleteSuggestion) string {
+func autocompleteSubtitle(suggestion gplace.AutocompleteSuggestion) (string, []gplace.TextMatch) {
 	if strings.TrimSpace(suggestion.SecondaryText) != "" {
-		return suggestion.SecondaryText
+		return suggestion.SecondaryText, suggestion.SecondaryTextMatches
 	}
 	if strings.TrimSpace(suggestion.Text) == "" || strings.TrimSpace(suggestion.MainText) == "" {
-		return ""
+		return "", nil
+	}
+	return suggestion.Text, suggestion.TextMatches
+}
+
+// highlightMatches styles matched ranges with highlight and the rest with base.
+// Offsets are Unicode characters, so slice by rune rather than byte.
+func highlightMatches(value string, matches []gplace.TextMatch, base, highlight func(string) string) string {
+	if len(matches) == 0 {
+		return base(value)
+	}
+	ranges := append([]gplace.TextMatch(nil), matches...)
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].StartOffset < ranges[j].StartOffset
+	})
+
+	runes := []rune(value)
+	var out strings.Builder
+	cursor := 0
+	for _, match := range ranges {
+		start := max(match.StartOffset, cursor)
+		end := min(match.EndOffset, len(runes))
+		if start >= end {
+			continue
+		}
+		if start > cursor {
+			out.WriteString(base(string(runes[cursor:start])))
+		}
+		out.WriteString(highlight(string(runes[start:end])))
+		cursor = end
+	}
+	if cursor < len(runes) {
+		out.WriteString(base(string(runes[cursor:])))
 	}
-	return suggestion.Text
+	return out.String()
 }
 
 func writePlaceSummary(out *bytes.Buffer, color Color, place gplace.PlaceSummary) {
@@ -247,6 +464,13 @@ func writePlaceDetails(out *bytes.Buffer, color Color, place gplace.PlaceDetails
 }
 
 func writeAmenities(out *bytes.Buffer, color Color, place gplace.PlaceDetails) {
+	if amenities := amenityLabels(place); len(amenities) > 0 {
+		writeLine(out, color, "Serves", strings.Join(amenities, ", "))
+	}
+}
+
+// amenityLabels lists what a place serves, in a fixed order.
+func amenityLabels(place gplace.PlaceDetails) []string {
 	var amenities []string
 	add := func(val *bool, label string) {
 		if val != nil && *val {
@@ -264,10 +488,7 @@ func writeAmenities(out *bytes.Buffer, color Color, place gplace.PlaceDetails) {
 	add(place.ServesLunch, "Lunch")
 	add(place.ServesVegetarianFood, "Vegetarian")
 	add(place.ServesWine, "Wine")
-
-	if len(amenities) > 0 {
-		writeLine(out, color, "Serves", strings.Join(amenities, ", "))
-	}
+	return amenities
 }
 
 func writeResolvedLocation(out *bytes.Buffer, color Color, place gplace.ResolvedLocation) {
@@ -333,11 +554,23 @@ func writeRating(out *bytes.Buffer, color Color, rating *float64, count *int, pr
 	if priceRange != nil {
 		parts = append(parts, formatPriceRange(priceRange))
 	} else if priceLevel != nil {
-		parts = append(parts, strings.Repeat("$", *priceLevel))
+		parts = append(parts, formatPriceLevel(*priceLevel))
 	}
 	writeLine(out, color, "Rating", strings.Join(parts, " · "))
 }
 
+// formatStars renders a 0-5 rating as five stars, rounded to the nearest
+// whole star.
+func formatStars(rating float64) string {
+	full := int(math.Round(math.Min(math.Max(rating, 0), 5)))
+	return strings.Repeat("★", full) + strings.Repeat("☆", 5-full)
+}
+
+// formatPriceLevel renders a 0-4 price level as "$" signs; 0 (free) is empty.
+func formatPriceLevel(level int) string {
+	return strings.Repeat("$", min(max(level, 0), 4))
+}
+
 func formatPriceRange(pr *gplace.PriceRange) string {
 	if pr == nil {
 		return ""
@@ -77,6 +77,52 @@ func TestRenderAutocomplete(t *testing.T) {
 	}
 }
 
+func TestRenderAutocompleteHighlightsMatches(t *testing.T) {
+	response := gplace.AutocompleteResponse{
+		Suggestions: []gplace.AutocompleteSuggestion{
+			{
+				Kind:                 "place",
+				MainText:             "Blue Bottle",
+				MainTextMatches:      []gplace.TextMatch{{StartOffset: 0, EndOffset: 4}, {StartOffset: 5, EndOffset: 8}},
+				SecondaryText:        "Oakland",
+				SecondaryTextMatches: []gplace.TextMatch{{StartOffset: 0, EndOffset: 3}},
+			},
+		},
+	}
+	output := renderAutocomplete(NewColor(true), response)
+	if !strings.Contains(output, "\x1b[1m\x1b[36mBlue\x1b[0m\x1b[0m") {
+		t.Fatalf("missing highlighted main text: %q", output)
+	}
+	if !strings.Contains(output, "\x1b[36m \x1b[0m\x1b[1m\x1b[36mBot\x1b[0m\x1b[0m\x1b[36mtle\x1b[0m") {
+		t.Fatalf("missing second highlighted range: %q", output)
+	}
+	if !strings.Contains(output, "\x1b[1mOak\x1b[0mland") {
+		t.Fatalf("missing highlighted secondary text: %q", output)
+	}
+
+	plain := renderAutocomplete(NewColor(false), response)
+	if !strings.Contains(plain, "Blue Bottle — Oakland") {
+		t.Fatalf("unexpected plain output: %s", plain)
+	}
+}
+
+func TestHighlightMatchesRunes(t *testing.T) {
+	brackets := func(value string) string { return "[" + value + "]" }
+	plain := func(value string) string { return value }
+
+	got := highlightMatches("渋谷駅前", []gplace.TextMatch{{StartOffset: 0, EndOffset: 2}}, plain, brackets)
+	if got != "[渋谷]駅前" {
+		t.Fatalf("unexpected rune highlight: %s", got)
+	}
+	got = highlightMatches("cafe", []gplace.TextMatch{{StartOffset: 2, EndOffset: 10}, {StartOffset: 0, EndOffset: 3}}, plain, brackets)
+	if got != "[caf][e]" {
+		t.Fatalf("unexpected overlapping highlight: %s", got)
+	}
+	if got := highlightMatches("cafe", nil, plain, brackets); got != "cafe" {
+		t.Fatalf("unexpected unmatched output: %s", got)
+	}
+}
+
 func TestRenderAutocompleteEmpty(t *testing.T) {
 	output := renderAutocomplete(NewColor(false), gplace.AutocompleteResponse{})
 	if !strings.Contains(output, "No results") {
@@ -112,7 +158,7 @@ func TestRenderRoute(t *testing.T) {
 			},
 		},
 	}
-	output := renderRoute(NewColor(false), response)
+	output := renderRoute(NewColor(false), response, false)
 	if !strings.Contains(output, "Route waypoints") {
 		t.Fatalf("missing route header")
 	}
@@ -124,13 +170,102 @@ func TestRenderRoute(t *testing.T) {
 	}
 }
 
+func TestRenderRoutePlaces(t *testing.T) {
+	along := 12300.0
+	offset := 150.0
+	waypoint := 1
+	response := gplace.RouteResponse{
+		Waypoints: []gplace.RouteWaypoint{
+			{Location: gplace.LatLng{Lat: 1, Lng: 2}, Results: []gplace.PlaceSummary{{PlaceID: "place-1", Name: "Cafe"}}},
+			{Location: gplace.LatLng{Lat: 3, Lng: 4}, Results: []gplace.PlaceSummary{{PlaceID: "place-1", Name: "Cafe"}}},
+		},
+		Places: []gplace.RoutePlace{
+			{
+				PlaceSummary:       gplace.PlaceSummary{PlaceID: "place-1", Name: "Cafe"},
+				WaypointIndex:      &waypoint,
+				DistanceAlongM:     &along,
+				DistanceFromRouteM: &offset,
+			},
+		},
+	}
+	output := renderRoute(NewColor(false), response, false)
+	if !strings.Contains(output, "Waypoint 2 (3.000000, 4.000000) · 1 results") {
+		t.Fatalf("missing compact waypoint line: %s", output)
+	}
+	if !strings.Contains(output, "Places along route (1)") {
+		t.Fatalf("missing places header: %s", output)
+	}
+	if strings.Count(output, "Cafe") != 1 {
+		t.Fatalf("expected place listed once: %s", output)
+	}
+	if !strings.Contains(output, "Route: near waypoint 2 · 12.3 km along · 150 m off route") {
+		t.Fatalf("missing route position: %s", output)
+	}
+}
+
+func TestRenderRouteNativePlaces(t *testing.T) {
+	seconds := 290
+	meters := 2400
+	response := gplace.RouteResponse{
+		Places: []gplace.RoutePlace{
+			{
+				PlaceSummary:  gplace.PlaceSummary{PlaceID: "place-1", Name: "Fuel"},
+				DetourSeconds: &seconds,
+				DetourMeters:  &meters,
+			},
+		},
+	}
+	output := renderRoute(NewColor(false), response, false)
+	if strings.Contains(output, "Route waypoints") {
+		t.Fatalf("unexpected waypoint header: %s", output)
+	}
+	if !strings.HasPrefix(output, "Places along route (1)") {
+		t.Fatalf("missing places header: %s", output)
+	}
+	if !strings.Contains(output, "Route: +5 min +2.4 km detour") {
+		t.Fatalf("missing detour: %s", output)
+	}
+}
+
 func TestRenderRouteEmpty(t *testing.T) {
-	output := renderRoute(NewColor(false), gplace.RouteResponse{})
+	output := renderRoute(NewColor(false), gplace.RouteResponse{}, false)
 	if !strings.Contains(output, "No results") {
 		t.Fatalf("unexpected output: %s", output)
 	}
 }
 
+func TestRenderRouteFilteredEmpty(t *testing.T) {
+	response := gplace.RouteResponse{
+		Waypoints: []gplace.RouteWaypoint{
+			{Location: gplace.LatLng{Lat: 1, Lng: 2}, Results: []gplace.PlaceSummary{{PlaceID: "place-1", Name: "Cafe"}}},
+		},
+	}
+	output := renderRoute(NewColor(false), response, true)
+	if strings.Contains(output, "Cafe") {
+		t.Fatalf("unexpected waypoint results: %s", output)
+	}
+	if !strings.Contains(output, "Waypoint 1 (1.000000, 2.000000) · 1 results") || !strings.HasSuffix(output, "No results.") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+}
+
+func TestRenderRouteSummary(t *testing.T) {
+	output := renderRouteSummary(NewColor(false), gplace.RouteInfo{
+		DistanceMeters:  182400,
+		DurationSeconds: 7230,
+		Polyline:        []gplace.LatLng{{}, {}},
+		Legs: []gplace.RouteLeg{
+			{DistanceMeters: 800, DurationSeconds: 45},
+			{DistanceMeters: 181600, DurationSeconds: 7185},
+		},
+	})
+	for _, want := range []string{"Distance: 182.4 km", "Duration: 2 h 1 min", "Polyline: 2 points", "Leg 1: 800 m · 45 s", "Leg 2: 181.6 km · 2 h 0 min"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("missing %q: %s", want, output)
+		}
+	}
+}
+
 func TestFormatTitleFallback(t *testing.T) {
 	title := formatTitle(NewColor(false), "", "")
 	if !strings.Contains(title, "(no name)") {
@@ -230,6 +365,15 @@ func TestColorEnabledTrue(t *testing.T) {
 	}
 }
 
+func TestFormatStarsAndPriceLevel(t *testing.T) {
+	if formatStars(4.4) != "★★★★☆" || formatStars(4.5) != "★★★★★" || formatStars(-1) != "☆☆☆☆☆" {
+		t.Fatalf("unexpected stars: %s %s %s", formatStars(4.4), formatStars(4.5), formatStars(-1))
+	}
+	if formatPriceLevel(2) != "$$" || formatPriceLevel(0) != "" || formatPriceLevel(9) != "$$$$" {
+		t.Fatalf("unexpected price levels")
+	}
+}
+
 func TestUniqueStrings(t *testing.T) {
 	values := uniqueStrings([]string{"cafe", "Cafe", "cafe", ""})
 	if len(values) != 2 {
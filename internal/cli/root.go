@@ -8,20 +8,37 @@ import (
 type Root struct {
 	Global       GlobalOptions   `embed:""`
 	Autocomplete AutocompleteCmd `cmd:"" help:"Autocomplete places and queries."`
+	Pick         PickCmd         `cmd:"" help:"Interactively pick a place as you type, then show its details."`
 	Nearby       NearbyCmd       `cmd:"" help:"Search nearby places by location."`
 	Search       SearchCmd       `cmd:"" help:"Search places by text query."`
 	Route        RouteCmd        `cmd:"" help:"Search places along a route."`
 	Details      DetailsCmd      `cmd:"" help:"Fetch place details by place ID."`
 	Resolve      ResolveCmd      `cmd:"" help:"Resolve a location string to candidate places."`
+	Ask          AskCmd          `cmd:"" help:"Search with a natural-language query, e.g. \"cheap ramen open late near Shibuya\"."`
+	Batch        BatchCmd        `cmd:"" help:"Run JSONL requests (search, nearby, autocomplete, details, resolve, route) concurrently."`
+	Enrich       EnrichCmd       `cmd:"" help:"Match CSV rows of business names and addresses to places."`
+	Dedupe       DedupeCmd       `cmd:"" help:"Merge duplicate places from saved search, nearby and route results."`
+	Auth         AuthCmd         `cmd:"" help:"Store, check or remove the API key."`
+	Serve        ServeCmd        `cmd:"" help:"Serve search, nearby, details, autocomplete, resolve and route as HTTP JSON endpoints."`
+	MCP          MCPCmd          `cmd:"" name:"mcp" help:"Serve search, nearby, details, autocomplete, resolve and route as Model Context Protocol tools on stdio."`
+	Types        TypesCmd        `cmd:"" help:"List place types by category, or search them (e.g. --search ramen)."`
+	Schema       SchemaCmd       `cmd:"" help:"Print function-calling tool definitions (OpenAI, Gemini or JSON Schema) for search, nearby, details, autocomplete, resolve and route."`
 }
 
 // GlobalOptions are flags shared by all commands.
 type GlobalOptions struct {
 	APIKey        string        `help:"Google Places API key." env:"GOOGLE_PLACES_API_KEY"`
+	APIKeyCmd     string        `name:"api-key-cmd" help:"Shell command that prints the API key (e.g. 'pass show google/places')." env:"GPLACE_API_KEY_CMD"`
 	BaseURL       string        `help:"Places API base URL." env:"GOOGLE_PLACES_BASE_URL" default:"https://places.googleapis.com/v1"`
 	RoutesBaseURL string        `help:"Routes API base URL." env:"GOOGLE_ROUTES_BASE_URL" default:"https://routes.googleapis.com"`
 	Timeout       time.Duration `help:"HTTP timeout." default:"10s"`
-	JSON          bool          `help:"Output JSON."`
+	Config        string        `help:"Config file with flag defaults and profiles; JSON only, not TOML or YAML (default $XDG_CONFIG_HOME/gplace/config.json)." env:"GPLACE_CONFIG"`
+	Profile       string        `help:"Config file profile to apply." env:"GPLACE_PROFILE"`
+	JSON          bool          `help:"Output JSON (same as --format json)."`
+	Format        string        `help:"Output format: text, json, ndjson, markdown, table, geojson, kml, gpx, csv, tsv." enum:"text,json,ndjson,markdown,table,geojson,kml,gpx,csv,tsv" default:"text"`
+	Columns       []string      `help:"Comma-separated columns for csv/tsv/table output (e.g. place_id,name,rating,lat,lng)."`
+	Template      string        `help:"Go template applied to each result, e.g. '{{.Name}} {{stars .Rating}}'."`
+	TemplateFile  string        `help:"File containing a Go template applied to each result." type:"path"`
 	NoColor       bool          `help:"Disable color output."`
 	Verbose       bool          `help:"Verbose logging."`
 	Version       VersionFlag   `name:"version" help:"Print version and exit."`
@@ -32,10 +49,11 @@ type SearchCmd struct {
 	Query       string   `arg:"" name:"query" help:"Search text."`
 	Limit       int      `help:"Max results (1-20)." default:"10"`
 	PageToken   string   `help:"Page token for pagination."`
+	Pages       int      `help:"Follow next_page_token for up to this many pages." default:"1"`
 	Language    string   `help:"BCP-47 language code (e.g. en, en-US)."`
 	Region      string   `help:"CLDR region code (e.g. US, DE)."`
 	Keyword     string   `help:"Keyword to append to the query."`
-	Type        []string `help:"Place type filter (includedType). Repeatable."`
+	Type        []string `help:"Place type filter (includedType); see gplace types. Repeatable."`
 	OpenNow     *bool    `help:"Return only currently open places."`
 	MinRating   *float64 `help:"Minimum rating (0-5)."`
 	PriceLevel  []int    `help:"Price levels 0-4. Repeatable."`
@@ -60,7 +78,7 @@ type AutocompleteCmd struct {
 // NearbyCmd runs nearby searches.
 type NearbyCmd struct {
 	Limit       int      `help:"Max results (1-20)." default:"10"`
-	Type        []string `help:"Included place types. Repeatable."`
+	Type        []string `help:"Included place types; see gplace types. Repeatable."`
 	ExcludeType []string `help:"Excluded place types. Repeatable."`
 	Language    string   `help:"BCP-47 language code (e.g. en, en-US)."`
 	Region      string   `help:"CLDR region code (e.g. US, DE)."`
@@ -72,11 +90,12 @@ type NearbyCmd struct {
 
 // DetailsCmd fetches place details.
 type DetailsCmd struct {
-	PlaceID  string `arg:"" name:"place_id" help:"Place ID."`
-	Language string `help:"BCP-47 language code (e.g. en, en-US)."`
-	Region   string `help:"CLDR region code (e.g. US, DE)."`
-	Reviews  bool   `help:"Include reviews in the response."`
-	Local    bool   `help:"Auto-detect local language (two-pass lookup)."`
+	PlaceID      string `arg:"" name:"place_id" help:"Place ID."`
+	Language     string `help:"BCP-47 language code (e.g. en, en-US)."`
+	Region       string `help:"CLDR region code (e.g. US, DE)."`
+	Reviews      bool   `help:"Include reviews in the response."`
+	Local        bool   `help:"Auto-detect local language (two-pass lookup)."`
+	SessionToken string `help:"Autocomplete session token to close the session."`
 }
 
 // ResolveCmd resolves a location string into candidates.
@@ -3,35 +3,110 @@ package cli
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/qztseng/gplace"
 )
 
 // RouteCmd searches along a route between two locations.
 type RouteCmd struct {
-	Query        string  `arg:"" name:"query" help:"Search text."`
-	From         string  `help:"Origin location (address or place name)."`
-	To           string  `help:"Destination location (address or place name)."`
-	Mode         string  `help:"Travel mode: DRIVE, WALK, BICYCLE, TWO_WHEELER, TRANSIT." default:"DRIVE"`
-	RadiusM      float64 `help:"Search radius in meters." default:"1000"`
-	MaxWaypoints int     `help:"Max sampled waypoints along the route." default:"5"`
-	Limit        int     `help:"Max results per waypoint (1-20)." default:"5"`
-	Language     string  `help:"BCP-47 language code (e.g. en, en-US)."`
-	Region       string  `help:"CLDR region code (e.g. US, DE)."`
+	Query         string        `arg:"" optional:"" name:"query" help:"Search text (optional with --show-route)."`
+	From          string        `help:"Origin: address, place_id:ID, or lat,lng."`
+	FromPlaceID   string        `help:"Origin place ID."`
+	To            string        `help:"Destination: address, place_id:ID, or lat,lng."`
+	ToPlaceID     string        `help:"Destination place ID."`
+	Via           []string      `help:"Intermediate stop: address, place_id:ID, or lat,lng. Repeatable." sep:"none"`
+	Mode          string        `help:"Travel mode: DRIVE, WALK, BICYCLE, TWO_WHEELER, TRANSIT." default:"DRIVE"`
+	Strategy      string        `help:"Route search strategy: sample (search around waypoints) or native (search along route polyline)." enum:"sample,native" default:"sample"`
+	Avoid         []string      `help:"Avoid tolls, highways or ferries (DRIVE, TWO_WHEELER). Repeatable."`
+	DepartureTime *time.Time    `help:"Departure time (RFC 3339, e.g. 2026-05-01T09:00:00-07:00)."`
+	RadiusM       float64       `help:"Search radius in meters." default:"1000"`
+	MaxWaypoints  int           `help:"Max sampled waypoints, one search each (default 5, or 25 with distance/dense sampling)."`
+	Sampling      string        `help:"Waypoint sampling: even (spread max-waypoints), distance (every spacing-m), or dense (distance, favoring city segments when over budget)."`
+	SpacingM      float64       `help:"Meters between sampled waypoints (default 2x radius-m); implies distance sampling."`
+	Concurrency   int           `help:"Parallel waypoint searches." default:"4"`
+	Limit         int           `help:"Max results per waypoint (1-20)." default:"5"`
+	Language      string        `help:"BCP-47 language code (e.g. en, en-US)."`
+	Region        string        `help:"CLDR region code (e.g. US, DE)."`
+	Detours       bool          `help:"Compute detour time and distance for each place (Routes API route matrix)."`
+	MaxDetour     time.Duration `help:"Drop places with a longer detour (e.g. 10m); implies --detours."`
+	Sort          string        `help:"Order places by route (distance along the route) or detour; detour implies --detours."`
+	ShowRoute     bool          `help:"Print the route distance, duration and legs; works without a query."`
 }
 
 // Run executes the route command.
 func (c *RouteCmd) Run(app *App) error {
 	request := gplace.RouteRequest{
-		Query:        c.Query,
-		From:         c.From,
-		To:           c.To,
-		Mode:         c.Mode,
-		RadiusM:      c.RadiusM,
-		MaxWaypoints: c.MaxWaypoints,
-		Limit:        c.Limit,
-		Language:     c.Language,
-		Region:       c.Region,
+		Query:         c.Query,
+		FromPlaceID:   c.FromPlaceID,
+		ToPlaceID:     c.ToPlaceID,
+		Mode:          c.Mode,
+		Strategy:      c.Strategy,
+		DepartureTime: c.DepartureTime,
+		RadiusM:       c.RadiusM,
+		MaxWaypoints:  c.MaxWaypoints,
+		Sampling:      c.Sampling,
+		SpacingM:      c.SpacingM,
+		Concurrency:   c.Concurrency,
+		Limit:         c.Limit,
+		Language:      c.Language,
+		Region:        c.Region,
+		Detours:       c.Detours,
+		SortBy:        c.Sort,
+	}
+	if c.MaxDetour < 0 {
+		return gplace.ValidationError{Field: "max_detour", Message: "must be >= 0"}
+	}
+	request.MaxDetourSeconds = int(math.Ceil(c.MaxDetour.Seconds()))
+
+	// --from place_id:X would otherwise overwrite --from-place-id.
+	if c.From != "" && c.FromPlaceID != "" {
+		return gplace.ValidationError{Field: "from", Message: "set only one of --from or --from-place-id"}
+	}
+	if c.To != "" && c.ToPlaceID != "" {
+		return gplace.ValidationError{Field: "to", Message: "set only one of --to or --to-place-id"}
+	}
+	if c.From != "" {
+		origin := parseRouteLocation(c.From)
+		request.From, request.FromLocation = origin.Address, origin.Location
+		if origin.PlaceID != "" {
+			request.FromPlaceID = origin.PlaceID
+		}
+	}
+	if c.To != "" {
+		destination := parseRouteLocation(c.To)
+		request.To, request.ToLocation = destination.Address, destination.Location
+		if destination.PlaceID != "" {
+			request.ToPlaceID = destination.PlaceID
+		}
+	}
+	for _, stop := range c.Via {
+		request.Via = append(request.Via, parseRouteLocation(stop))
+	}
+
+	modifiers, err := parseAvoid(c.Avoid)
+	if err != nil {
+		return err
+	}
+	request.Modifiers = modifiers
+
+	if c.ShowRoute && strings.TrimSpace(c.Query) == "" {
+		route, err := app.client.ComputeRoute(context.Background(), request)
+		if err != nil {
+			return err
+		}
+		if app.format != formatText {
+			return outputRoute(app, gplace.RouteResponse{Route: &route, Waypoints: []gplace.RouteWaypoint{}})
+		}
+		_, err = fmt.Fprintln(app.out, renderRouteSummary(app.color, route))
+		return err
+	}
+
+	if app.format == formatNDJSON {
+		return streamRoute(app, request)
 	}
 
 	response, err := app.client.Route(context.Background(), request)
@@ -39,10 +114,77 @@ func (c *RouteCmd) Run(app *App) error {
 		return err
 	}
 
-	if app.json {
-		return writeJSON(app.out, response)
+	if app.format != formatText {
+		return outputRoute(app, response)
 	}
 
-	_, err = fmt.Fprintln(app.out, renderRoute(app.color, response))
+	places := request.Detours || request.MaxDetourSeconds > 0 || request.SortBy != ""
+	output := renderRoute(app.color, response, places)
+	if c.ShowRoute && response.Route != nil {
+		output = renderRouteSummary(app.color, *response.Route) + "\n\n" + output
+	}
+	_, err = fmt.Fprintln(app.out, output)
 	return err
 }
+
+// streamRoute writes the route and each waypoint as NDJSON records as soon as
+// they are available, then the deduplicated places once the search is done.
+func streamRoute(app *App, request gplace.RouteRequest) error {
+	var writeErr error
+	write := func(record ndjsonRecord) {
+		if writeErr == nil {
+			writeErr = writeRecord(app.out, record)
+		}
+	}
+	response, err := app.client.RouteWithProgress(context.Background(), request, gplace.RouteProgress{
+		Route: func(route gplace.RouteInfo) {
+			write(ndjsonRecord{Type: recordRoute, Data: route})
+		},
+		Waypoint: func(index int, waypoint gplace.RouteWaypoint) {
+			write(ndjsonRecord{Type: recordWaypoint, Index: &index, Data: waypoint})
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return writeRecords(app.out, recordPlace, response.Places)
+}
+
+// parseRouteLocation reads "place_id:ID", "lat,lng", or falls back to an address.
+func parseRouteLocation(value string) gplace.RouteLocation {
+	value = strings.TrimSpace(value)
+	if id, ok := strings.CutPrefix(value, "place_id:"); ok {
+		return gplace.RouteLocation{PlaceID: strings.TrimSpace(id)}
+	}
+	if latText, lngText, ok := strings.Cut(value, ","); ok {
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+		if latErr == nil && lngErr == nil {
+			return gplace.RouteLocation{Location: &gplace.LatLng{Lat: lat, Lng: lng}}
+		}
+	}
+	return gplace.RouteLocation{Address: value}
+}
+
+func parseAvoid(values []string) (*gplace.RouteModifiers, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	modifiers := &gplace.RouteModifiers{}
+	for _, value := range values {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "tolls":
+			modifiers.AvoidTolls = true
+		case "highways":
+			modifiers.AvoidHighways = true
+		case "ferries":
+			modifiers.AvoidFerries = true
+		default:
+			return nil, gplace.ValidationError{Field: "avoid", Message: "must be tolls, highways, or ferries"}
+		}
+	}
+	return modifiers, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"text/template"
 
 	"github.com/alecthomas/kong"
 	"github.com/qztseng/gplace"
@@ -15,14 +16,26 @@ import (
 // App wires CLI output and API access.
 type App struct {
 	client *gplace.Client
-	out    io.Writer
-	err    io.Writer
-	json   bool
-	color  Color
+	// apiKey and keySource are the key the client uses and where it came
+	// from, for auth status.
+	apiKey    string
+	keySource string
+	in        *os.File
+	out       io.Writer
+	err       io.Writer
+	format    string
+	columns   []string
+	template  *template.Template
+	color     Color
 }
 
 // Run executes the CLI with the provided arguments.
 func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	return run(args, os.Stdin, stdout, stderr)
+}
+
+// run is Run reading input from stdin.
+func run(args []string, stdin *os.File, stdout io.Writer, stderr io.Writer) int {
 	if stdout == nil {
 		stdout = os.Stdout
 	}
@@ -31,6 +44,7 @@ func Run(args []string, stdout io.Writer, stderr io.Writer) int {
 	}
 
 	root := Root{}
+	config := &configResolver{}
 	exitCode := 0
 	parser, err := kong.New(
 		&root,
@@ -44,6 +58,7 @@ func Run(args []string, stdout io.Writer, stderr io.Writer) int {
 			panic(exitSignal{code: code})
 		}),
 		kong.Vars{"version": Version},
+		kong.Resolvers(config),
 	)
 	if err != nil {
 		_, _ = fmt.Fprintln(stderr, err)
@@ -63,34 +78,81 @@ func Run(args []string, stdout io.Writer, stderr io.Writer) int {
 		_, _ = fmt.Fprintln(stderr, err)
 		return 2
 	}
-	if root.Global.JSON {
-		// JSON output should never include ANSI escapes.
+	if err := config.check(parser.Model, root.Global.Profile); err != nil {
+		return handleError(stderr, err)
+	}
+	format := root.Global.Format
+	if root.Global.JSON && format == formatText {
+		format = formatJSON
+	}
+	tmpl, err := loadTemplate(root.Global.Template, root.Global.TemplateFile)
+	if err != nil {
+		return handleError(stderr, err)
+	}
+	if tmpl != nil {
+		if format != formatText {
+			return handleError(stderr, gplace.ValidationError{Field: "template", Message: "cannot be combined with --format or --json"})
+		}
+		format = formatTemplate
+	}
+	if len(root.Global.Columns) > 0 && format != formatCSV && format != formatTSV && format != formatTable {
+		return handleError(stderr, gplace.ValidationError{Field: "columns", Message: "requires --format csv, tsv or table"})
+	}
+	if format != formatText {
+		// Machine-readable output should never include ANSI escapes.
 		root.Global.NoColor = true
 	}
 
+	apiKey, keySource := root.Global.APIKey, ""
+	if command, ok := selectedCommand(ctx).(offlineCommand); !ok || !command.offline() {
+		apiKey, keySource, err = resolveAPIKey(ctx, root.Global, stderr)
+		if err != nil {
+			return handleError(stderr, err)
+		}
+	}
+
 	client := gplace.NewClient(gplace.Options{
-		APIKey:        root.Global.APIKey,
+		APIKey:        apiKey,
 		BaseURL:       root.Global.BaseURL,
 		RoutesBaseURL: root.Global.RoutesBaseURL,
 		Timeout:       root.Global.Timeout,
 	})
 
 	app := &App{
-		client: client,
-		out:    stdout,
-		err:    stderr,
-		json:   root.Global.JSON,
-		color:  NewColor(colorEnabled(root.Global.NoColor)),
+		client:    client,
+		apiKey:    apiKey,
+		keySource: keySource,
+		in:        stdin,
+		out:       stdout,
+		err:       stderr,
+		format:    format,
+		columns:   root.Global.Columns,
+		template:  tmpl,
+		color:     NewColor(colorEnabled(root.Global.NoColor)),
 	}
 
 	ctx.Bind(app)
 	if err := ctx.Run(); err != nil {
+		if format == formatNDJSON {
+			// Keep the stream parseable: the error is the last record.
+			_ = writeRecord(stdout, errorRecord(err))
+			return errorExitCode(err)
+		}
 		return handleError(stderr, err)
 	}
 
 	return 0
 }
 
+// selectedCommand returns the command struct kong will run, or nil.
+func selectedCommand(ctx *kong.Context) any {
+	node := ctx.Selected()
+	if node == nil || !node.Target.CanAddr() {
+		return nil
+	}
+	return node.Target.Addr().Interface()
+}
+
 type exitSignal struct {
 	code int
 }
@@ -162,6 +224,10 @@ func (c *SearchCmd) Run(app *App) error {
 		}
 	}
 
+	if c.Pages < 1 {
+		return gplace.ValidationError{Field: "pages", Message: "must be at least 1"}
+	}
+
 	response, err := app.client.Search(context.Background(), request)
 	if err != nil {
 		return err
@@ -184,22 +250,75 @@ func (c *SearchCmd) Run(app *App) error {
 		}
 	}
 
-	if app.json {
-		if err := writeJSON(app.out, response.Results); err != nil {
+	if app.format == formatNDJSON {
+		// Stream each page as it arrives.
+		response, err = searchPages(context.Background(), app.client, request, response, c.Pages, func(page gplace.SearchResponse) error {
+			return writeRecords(app.out, recordPlace, page.Results)
+		})
+		if err != nil {
 			return err
 		}
-		if response.NextPageToken != "" {
-			_, _ = fmt.Fprintln(app.err, "next_page_token:", response.NextPageToken)
+		return writePageToken(app.out, response.NextPageToken)
+	}
+
+	response, err = searchPages(context.Background(), app.client, request, response, c.Pages, nil)
+	if err != nil {
+		return err
+	}
+
+	if app.format != formatText {
+		if err := outputPlaces(app, response.Results); err != nil {
+			return err
 		}
-		return nil
+		return outputPageToken(app, response.NextPageToken)
 	}
 
 	_, err = fmt.Fprintln(app.out, renderSearch(app.color, response))
 	return err
 }
 
+// searchPages follows next_page_token from first until pages pages have been
+// fetched, passing each page (including first) to emit as it arrives. The
+// returned response holds every result and the last page's token.
+func searchPages(
+	ctx context.Context,
+	client *gplace.Client,
+	request gplace.SearchRequest,
+	first gplace.SearchResponse,
+	pages int,
+	emit func(gplace.SearchResponse) error,
+) (gplace.SearchResponse, error) {
+	combined := first
+	page := first
+	for fetched := 1; ; fetched++ {
+		if emit != nil {
+			if err := emit(page); err != nil {
+				return combined, err
+			}
+		}
+		if fetched >= pages || page.NextPageToken == "" {
+			return combined, nil
+		}
+		request.PageToken = page.NextPageToken
+		next, err := client.Search(ctx, request)
+		if err != nil {
+			return combined, err
+		}
+		page = next
+		combined.Results = append(combined.Results, next.Results...)
+		combined.RoutingSummaries = append(combined.RoutingSummaries, next.RoutingSummaries...)
+		combined.NextPageToken = next.NextPageToken
+	}
+}
+
 // Run executes the autocomplete command.
 func (c *AutocompleteCmd) Run(app *App) error {
+	switch app.format {
+	case formatText, formatJSON, formatNDJSON, formatTemplate:
+	default:
+		return unsupportedFormat(app.format, "autocomplete")
+	}
+
 	request := gplace.AutocompleteRequest{
 		Input:        c.Input,
 		Limit:        c.Limit,
@@ -224,8 +343,13 @@ func (c *AutocompleteCmd) Run(app *App) error {
 		return err
 	}
 
-	if app.json {
+	switch app.format {
+	case formatJSON:
 		return writeJSON(app.out, response.Suggestions)
+	case formatNDJSON:
+		return writeRecords(app.out, recordSuggestion, response.Suggestions)
+	case formatTemplate:
+		return writeTemplate(app, response.Suggestions)
 	}
 
 	_, err = fmt.Fprintln(app.out, renderAutocomplete(app.color, response))
@@ -273,14 +397,11 @@ func (c *NearbyCmd) Run(app *App) error {
 		}
 	}
 
-	if app.json {
-		if err := writeJSON(app.out, response.Results); err != nil {
+	if app.format != formatText {
+		if err := outputPlaces(app, response.Results); err != nil {
 			return err
 		}
-		if response.NextPageToken != "" {
-			_, _ = fmt.Fprintln(app.err, "next_page_token:", response.NextPageToken)
-		}
-		return nil
+		return outputPageToken(app, response.NextPageToken)
 	}
 
 	_, err = fmt.Fprintln(app.out, renderNearby(app.color, response))
@@ -307,13 +428,14 @@ func (c *DetailsCmd) Run(app *App) error {
 		Language:       language,
 		Region:         c.Region,
 		IncludeReviews: c.Reviews,
+		SessionToken:   c.SessionToken,
 	})
 	if err != nil {
 		return err
 	}
 
-	if app.json {
-		return writeJSON(app.out, response)
+	if app.format != formatText {
+		return outputDetails(app, response)
 	}
 
 	_, err = fmt.Fprintln(app.out, renderDetails(app.color, response))
@@ -334,8 +456,8 @@ func (c *ResolveCmd) Run(app *App) error {
 		return err
 	}
 
-	if app.json {
-		return writeJSON(app.out, response.Results)
+	if app.format != formatText {
+		return outputResolved(app, response.Results)
 	}
 
 	_, err = fmt.Fprintln(app.out, renderResolve(app.color, response))
@@ -355,15 +477,16 @@ func handleError(writer io.Writer, err error) int {
 	if err == nil {
 		return 0
 	}
+	_, _ = fmt.Fprintln(writer, err.Error())
+	return errorExitCode(err)
+}
+
+// errorExitCode is 2 for usage problems (invalid input, missing API key) and 1
+// for everything else.
+func errorExitCode(err error) int {
 	var validation gplace.ValidationError
-	if errors.As(err, &validation) {
-		_, _ = fmt.Fprintln(writer, validation.Error())
+	if errors.As(err, &validation) || errors.Is(err, gplace.ErrMissingAPIKey) {
 		return 2
 	}
-	if errors.Is(err, gplace.ErrMissingAPIKey) {
-		_, _ = fmt.Fprintln(writer, err.Error())
-		return 2
-	}
-	_, _ = fmt.Fprintln(writer, err.Error())
 	return 1
 }
@@ -0,0 +1,94 @@
+package cli
+
+// Schema export targets.
+const (
+	schemaTargetOpenAI     = "openai"
+	schemaTargetGemini     = "gemini"
+	schemaTargetJSONSchema = "jsonschema"
+)
+
+// SchemaCmd prints function-calling tool definitions for the agent tools.
+type SchemaCmd struct {
+	Target string `help:"Tool definition format: openai (Chat Completions tools), gemini (functionDeclarations) or jsonschema (name, description, input_schema)." enum:"openai,gemini,jsonschema" default:"jsonschema"`
+}
+
+func (*SchemaCmd) offline() bool { return true }
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  *jsonSchema `json:"parameters"`
+}
+
+type geminiTools struct {
+	FunctionDeclarations []geminiFunction `json:"functionDeclarations"`
+}
+
+type geminiFunction struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  *jsonSchema `json:"parameters"`
+}
+
+type schemaTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema *jsonSchema `json:"input_schema"`
+}
+
+// Run executes the schema command.
+func (c *SchemaCmd) Run(app *App) error {
+	if app.format != formatText && app.format != formatJSON {
+		return unsupportedFormat(app.format, "schema")
+	}
+	switch c.Target {
+	case schemaTargetOpenAI:
+		tools := make([]openAITool, 0, len(agentTools))
+		for _, tool := range agentTools {
+			tools = append(tools, openAITool{Type: "function", Function: openAIFunction{
+				Name:        tool.name,
+				Description: tool.description,
+				Parameters:  tool.inputSchema(),
+			}})
+		}
+		return writeJSON(app.out, tools)
+	case schemaTargetGemini:
+		declarations := make([]geminiFunction, 0, len(agentTools))
+		for _, tool := range agentTools {
+			declarations = append(declarations, geminiFunction{
+				Name:        tool.name,
+				Description: tool.description,
+				Parameters:  geminiSchema(tool.inputSchema()),
+			})
+		}
+		return writeJSON(app.out, geminiTools{FunctionDeclarations: declarations})
+	default:
+		tools := make([]schemaTool, 0, len(agentTools))
+		for _, tool := range agentTools {
+			tools = append(tools, schemaTool{Name: tool.name, Description: tool.description, InputSchema: tool.inputSchema()})
+		}
+		return writeJSON(app.out, tools)
+	}
+}
+
+// geminiSchema rewrites schema in place for Gemini's OpenAPI subset, which
+// has no additionalProperties and only allows enums on strings. Enum values
+// of other types remain in the descriptions.
+func geminiSchema(schema *jsonSchema) *jsonSchema {
+	schema.AdditionalProperties = nil
+	if schema.Type != "string" {
+		schema.Enum = nil
+	}
+	if schema.Items != nil {
+		geminiSchema(schema.Items)
+	}
+	for _, property := range schema.Properties {
+		geminiSchema(property)
+	}
+	return schema
+}
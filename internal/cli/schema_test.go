@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRunSchemaTargets(t *testing.T) {
+	run := func(target string, into any) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		// No API key is needed to print schemas.
+		t.Setenv("GOOGLE_PLACES_API_KEY", "")
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		if code := Run([]string{"schema", "--target", target}, &stdout, &stderr); code != 0 {
+			t.Fatalf("%s: exit code %d (stderr=%s)", target, code, stderr.String())
+		}
+		if err := json.Unmarshal(stdout.Bytes(), into); err != nil {
+			t.Fatalf("%s: parse output: %v", target, err)
+		}
+	}
+
+	var openai []openAITool
+	run("openai", &openai)
+	if len(openai) != len(agentTools) || openai[0].Type != "function" || openai[0].Function.Name != "search_places" {
+		t.Fatalf("unexpected openai tools %+v", openai)
+	}
+	if prices := openai[0].Function.Parameters.Properties["filters"].Properties["price_levels"]; len(prices.Items.Enum) != 5 {
+		t.Fatalf("expected price level enum, got %+v", prices.Items)
+	}
+
+	var gemini geminiTools
+	run("gemini", &gemini)
+	if len(gemini.FunctionDeclarations) != len(agentTools) {
+		t.Fatalf("unexpected gemini declarations %+v", gemini)
+	}
+	search := gemini.FunctionDeclarations[0].Parameters
+	if search.AdditionalProperties != nil || search.Properties["filters"].Properties["price_levels"].Items.Enum != nil {
+		t.Fatalf("expected additionalProperties and integer enums removed for gemini, got %+v", search)
+	}
+	route := gemini.FunctionDeclarations[len(agentTools)-1]
+	if route.Name != "route_search" || len(route.Parameters.Properties["mode"].Enum) != 5 {
+		t.Fatalf("expected travel mode enum kept for gemini, got %+v", route.Parameters.Properties["mode"])
+	}
+
+	var generic []schemaTool
+	run("jsonschema", &generic)
+	if len(generic) != len(agentTools) || generic[2].Name != "place_details" || generic[2].InputSchema.Required[0] != "place_id" {
+		t.Fatalf("unexpected jsonschema tools %+v", generic)
+	}
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/qztseng/gplace"
+)
+
+const (
+	// maxServeBody bounds one request body, like one batch line.
+	maxServeBody = maxBatchLine
+	// serveShutdownTimeout is how long in-flight requests get to finish
+	// after SIGINT or SIGTERM.
+	serveShutdownTimeout = 10 * time.Second
+)
+
+// ServeCmd exposes gplace operations over HTTP.
+type ServeCmd struct {
+	Addr       string        `help:"Listen address; listening beyond localhost requires --tokens-file." default:"localhost:8080"`
+	TokensFile string        `help:"File of caller tokens, one name:token per line (mode 0600). Callers send Authorization: Bearer <token>." type:"path"`
+	RateLimit  float64       `help:"Requests per minute per caller (0 disables)." default:"60"`
+	Burst      int           `help:"Requests a caller can make at once before --rate-limit applies." default:"10"`
+	CacheTTL   time.Duration `name:"cache-ttl" help:"Serve identical requests from memory for this long (0 disables; check the Google Maps Platform terms before caching Places content)." default:"0s"`
+	CacheSize  int           `help:"Max cached responses." default:"1000"`
+}
+
+// serveToken is one caller's bearer token.
+type serveToken struct {
+	name   string
+	secret []byte
+}
+
+// proxyServer answers JSON requests with the shared client, applying caller
+// tokens, per-caller rate limits and the response cache.
+type proxyServer struct {
+	client  *gplace.Client
+	tokens  []serveToken
+	limiter *rateLimiter
+	cache   *responseCache
+	log     *log.Logger
+}
+
+// Run executes the serve command.
+func (c *ServeCmd) Run(app *App) error {
+	if app.format != formatText {
+		return unsupportedFormat(app.format, "serve")
+	}
+	if c.RateLimit < 0 {
+		return gplace.ValidationError{Field: "rate_limit", Message: "must be >= 0"}
+	}
+	if c.Burst < 1 {
+		return gplace.ValidationError{Field: "burst", Message: "must be >= 1"}
+	}
+	if c.CacheTTL < 0 || c.CacheSize < 1 {
+		return gplace.ValidationError{Field: "cache", Message: "--cache-ttl must be >= 0 and --cache-size >= 1"}
+	}
+	if strings.TrimSpace(app.apiKey) == "" {
+		return gplace.ErrMissingAPIKey
+	}
+
+	var tokens []serveToken
+	if c.TokensFile != "" {
+		var err error
+		if tokens, err = readServeTokens(c.TokensFile); err != nil {
+			return err
+		}
+	} else if !loopbackAddr(c.Addr) {
+		return gplace.ValidationError{Field: "addr", Message: fmt.Sprintf("%s is reachable from other hosts; pass --tokens-file", c.Addr)}
+	}
+
+	server := &proxyServer{
+		client:  app.client,
+		tokens:  tokens,
+		limiter: newRateLimiter(c.RateLimit, c.Burst),
+		cache:   newResponseCache(c.CacheTTL, c.CacheSize),
+		log:     log.New(app.err, "", log.LstdFlags),
+	}
+	listener, err := net.Listen("tcp", c.Addr)
+	if err != nil {
+		return fmt.Errorf("gplace: listen: %w", err)
+	}
+	httpServer := &http.Server{
+		Handler:           server.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          server.log,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+	server.log.Printf("serving on http://%s", listener.Addr())
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("gplace: serve: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// routes maps each endpoint to the client method with the same request and
+// response types the library and batch use.
+func (s *proxyServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeServeJSON(w, http.StatusOK, []byte(`{"status":"ok"}`))
+	})
+	mux.Handle("POST /search", serveEndpoint(s, jsonBody[gplace.SearchRequest], s.client.Search))
+	mux.Handle("POST /nearby", serveEndpoint(s, jsonBody[gplace.NearbySearchRequest], s.client.NearbySearch))
+	mux.Handle("POST /autocomplete", serveEndpoint(s, jsonBody[gplace.AutocompleteRequest], s.client.Autocomplete))
+	mux.Handle("POST /resolve", serveEndpoint(s, jsonBody[gplace.LocationResolveRequest], s.client.Resolve))
+	mux.Handle("POST /route", serveEndpoint(s, jsonBody[gplace.RouteRequest], s.client.Route))
+	mux.Handle("GET /details/{id}", serveEndpoint(s, detailsQuery, s.client.DetailsWithOptions))
+	return mux
+}
+
+// serveEndpoint authenticates and rate-limits the caller, decodes the
+// request, then answers from the cache or the client. Each request is logged
+// with its caller.
+func serveEndpoint[Req any, Resp any](
+	s *proxyServer,
+	decode func(*http.Request) (Req, error),
+	call func(context.Context, Req) (Resp, error),
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		caller, authorized := s.caller(r)
+		status, source := http.StatusOK, "-"
+		defer func() {
+			s.log.Printf("%s %s %s %d %s %s", caller, r.Method, r.URL.Path, status, source, time.Since(started).Round(time.Millisecond))
+		}()
+
+		if !authorized {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			status = writeServeError(w, http.StatusUnauthorized, errors.New("gplace: missing or unknown bearer token"))
+			return
+		}
+		if wait, ok := s.limiter.allow(caller); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			status = writeServeError(w, http.StatusTooManyRequests, errors.New("gplace: rate limit exceeded"))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxServeBody)
+		req, err := decode(r)
+		if err != nil {
+			status = writeServeError(w, serveErrorStatus(err), err)
+			return
+		}
+
+		key := ""
+		if s.cache != nil {
+			// The typed request marshals canonically, so equivalent bodies
+			// share an entry.
+			payload, _ := json.Marshal(req)
+			key = r.URL.Path + " " + string(payload)
+			if body, ok := s.cache.get(key); ok {
+				source = "cache"
+				writeServeJSON(w, status, body)
+				return
+			}
+		}
+		source = "api"
+		response, err := call(r.Context(), req)
+		if err != nil {
+			status = writeServeError(w, serveErrorStatus(err), err)
+			return
+		}
+		body, err := json.Marshal(response)
+		if err != nil {
+			status = writeServeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if key != "" {
+			s.cache.put(key, body)
+		}
+		writeServeJSON(w, status, body)
+	})
+}
+
+// caller names the client behind r: its token's name, or its IP address when
+// no tokens are configured.
+func (s *proxyServer) caller(r *http.Request) (string, bool) {
+	if len(s.tokens) == 0 {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr, true
+		}
+		return host, true
+	}
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "-", false
+	}
+	name := ""
+	// Compare against every token so timing does not reveal which matched.
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(secret)), token.secret) == 1 {
+			name = token.name
+		}
+	}
+	if name == "" {
+		return "-", false
+	}
+	return name, true
+}
+
+// jsonBody decodes the request body strictly, like a batch line. The body is
+// read first so an oversized one fails as such rather than as bad JSON.
+func jsonBody[T any](r *http.Request) (T, error) {
+	var req T
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return req, fmt.Errorf("gplace: read request body: %w", err)
+	}
+	err = decodeRequest(bytes.NewReader(payload), &req)
+	return req, err
+}
+
+// detailsQuery reads a details request from the path and query string:
+// /details/{id}?language=&region=&include_reviews=&session_token=.
+func detailsQuery(r *http.Request) (gplace.DetailsRequest, error) {
+	query := r.URL.Query()
+	req := gplace.DetailsRequest{
+		PlaceID:      r.PathValue("id"),
+		Language:     query.Get("language"),
+		Region:       query.Get("region"),
+		SessionToken: query.Get("session_token"),
+	}
+	if value := query.Get("include_reviews"); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return req, gplace.ValidationError{Field: "include_reviews", Message: "must be true or false"}
+		}
+		req.IncludeReviews = include
+	}
+	return req, nil
+}
+
+// serveErrorStatus maps an error to the HTTP status returned to callers:
+// their own mistakes are 4xx, Google's rejections are 502.
+func serveErrorStatus(err error) int {
+	var validation gplace.ValidationError
+	var apiErr *gplace.APIError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &validation):
+		return http.StatusBadRequest
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &apiErr):
+		return http.StatusBadGateway
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeServeError writes an error record like the one batch and ndjson
+// output use, and returns status for logging.
+func writeServeError(w http.ResponseWriter, status int, err error) int {
+	body, _ := json.Marshal(errorRecord(err))
+	writeServeJSON(w, status, body)
+	return status
+}
+
+func writeServeJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// readServeTokens parses a tokens file: name:token lines, with blank lines
+// and # comments ignored.
+func readServeTokens(path string) ([]serveToken, error) {
+	payload, err := readPrivateFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []serveToken
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, secret, ok := strings.Cut(text, ":")
+		name, secret = strings.TrimSpace(name), strings.TrimSpace(secret)
+		if !ok || name == "" || secret == "" {
+			return nil, fmt.Errorf("gplace: %s:%d: expected name:token", path, line)
+		}
+		if seen[secret] {
+			return nil, fmt.Errorf("gplace: %s:%d: token of %s is already used", path, line, name)
+		}
+		seen[secret] = true
+		tokens = append(tokens, serveToken{name: name, secret: []byte(secret)})
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("gplace: %s has no tokens", path)
+	}
+	return tokens, nil
+}
+
+// loopbackAddr reports whether addr only accepts local connections.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
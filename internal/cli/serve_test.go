@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qztseng/gplace"
+)
+
+func newTestProxy(t *testing.T, tokens []serveToken, limiter *rateLimiter, cache *responseCache) (*httptest.Server, *atomic.Int32, *bytes.Buffer) {
+	t.Helper()
+	var upstreamCalls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls.Add(1)
+		switch {
+		case r.URL.Path == placesSearchPath:
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+		case r.URL.Path == "/places/abc":
+			if r.URL.Query().Get("languageCode") != "de" {
+				t.Errorf("expected language from query string, got %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"id":"abc","displayName":{"text":"Café"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"not found"}}`))
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	var logs bytes.Buffer
+	server := &proxyServer{
+		client:  gplace.NewClient(gplace.Options{APIKey: "test-key", BaseURL: upstream.URL}),
+		tokens:  tokens,
+		limiter: limiter,
+		cache:   cache,
+		log:     log.New(&logs, "", 0),
+	}
+	proxy := httptest.NewServer(server.routes())
+	t.Cleanup(proxy.Close)
+	return proxy, &upstreamCalls, &logs
+}
+
+func postJSON(t *testing.T, url string, token string, body string) (*http.Response, map[string]any) {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return doJSON(t, request)
+}
+
+func doJSON(t *testing.T, request *http.Request) (*http.Response, map[string]any) {
+	t.Helper()
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	payload, _ := io.ReadAll(response.Body)
+	var decoded map[string]any
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("decode %q: %v", payload, err)
+	}
+	return response, decoded
+}
+
+func TestServeEndpoints(t *testing.T) {
+	tokens := []serveToken{{name: "billing", secret: []byte("s3cret")}}
+	proxy, upstreamCalls, logs := newTestProxy(t, tokens, nil, newResponseCache(time.Minute, 10))
+
+	response, body := postJSON(t, proxy.URL+"/search", "s3cret", `{"query":"coffee","limit":1}`)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", response.StatusCode, body)
+	}
+	results, _ := body["results"].([]any)
+	if len(results) != 1 {
+		t.Fatalf("unexpected search response: %v", body)
+	}
+	// Same request with different key order is served from the cache.
+	if response, _ := postJSON(t, proxy.URL+"/search", "s3cret", `{"limit":1, "query":"coffee"}`); response.StatusCode != http.StatusOK {
+		t.Fatalf("expected cached 200, got %d", response.StatusCode)
+	}
+	if upstreamCalls.Load() != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", upstreamCalls.Load())
+	}
+	if !strings.Contains(logs.String(), "billing POST /search 200 cache") {
+		t.Fatalf("expected cache hit logged with caller, got %s", logs.String())
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, proxy.URL+"/details/abc?language=de", nil)
+	request.Header.Set("Authorization", "Bearer s3cret")
+	if response, body := doJSON(t, request); response.StatusCode != http.StatusOK || body["name"] != "Café" {
+		t.Fatalf("unexpected details response %d: %v", response.StatusCode, body)
+	}
+
+	response, body = postJSON(t, proxy.URL+"/search", "s3cret", `{"qeury":"coffee"}`)
+	if response.StatusCode != http.StatusBadRequest || body["field"] != "request" {
+		t.Fatalf("expected 400 for unknown field, got %d: %v", response.StatusCode, body)
+	}
+	response, body = postJSON(t, proxy.URL+"/nearby", "s3cret", `{"location_restriction":{"lat":1,"lng":2,"radius_m":100}}`)
+	if response.StatusCode != http.StatusBadGateway || body["status_code"] != float64(http.StatusNotFound) {
+		t.Fatalf("expected 502 with upstream status, got %d: %v", response.StatusCode, body)
+	}
+
+	oversized := `{"query":"` + strings.Repeat("a", maxServeBody) + `"}`
+	response, body = postJSON(t, proxy.URL+"/search", "s3cret", oversized)
+	if response.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for oversized body, got %d: %v", response.StatusCode, body)
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		response, _ := postJSON(t, proxy.URL+"/search", token, `{"query":"coffee"}`)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for token %q, got %d", token, response.StatusCode)
+		}
+	}
+}
+
+func TestServeRateLimit(t *testing.T) {
+	proxy, _, _ := newTestProxy(t, nil, newRateLimiter(60, 2), nil)
+	for i := range 2 {
+		if response, _ := postJSON(t, proxy.URL+"/search", "", `{"query":"coffee"}`); response.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, response.StatusCode)
+		}
+	}
+	response, _ := postJSON(t, proxy.URL+"/search", "", `{"query":"coffee"}`)
+	if response.StatusCode != http.StatusTooManyRequests || response.Header.Get("Retry-After") != "1" {
+		t.Fatalf("expected 429 with Retry-After 1, got %d %q", response.StatusCode, response.Header.Get("Retry-After"))
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(30, 1)
+	limiter.now = func() time.Time { return now }
+	if _, ok := limiter.allow("a"); !ok {
+		t.Fatalf("expected first request allowed")
+	}
+	if wait, ok := limiter.allow("a"); ok || wait != 2*time.Second {
+		t.Fatalf("expected 2s wait, got %v %v", wait, ok)
+	}
+	if _, ok := limiter.allow("b"); !ok {
+		t.Fatalf("expected other caller allowed")
+	}
+	now = now.Add(2 * time.Second)
+	if _, ok := limiter.allow("a"); !ok {
+		t.Fatalf("expected request allowed after refill")
+	}
+}
+
+func TestResponseCacheExpiresAndEvicts(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newResponseCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+	cache.put("a", []byte("1"))
+	cache.put("b", []byte("2"))
+	cache.get("a")
+	cache.put("c", []byte("3"))
+	if _, ok := cache.get("b"); ok {
+		t.Fatalf("expected least recently used entry evicted")
+	}
+	if body, ok := cache.get("a"); !ok || string(body) != "1" {
+		t.Fatalf("expected a cached")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("a"); ok {
+		t.Fatalf("expected entry expired")
+	}
+}
+
+func TestServeConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	tokens := filepath.Join(dir, "tokens")
+	if err := os.WriteFile(tokens, []byte("# callers\nbilling:abc\nreports abc\n"), 0o600); err != nil {
+		t.Fatalf("write tokens: %v", err)
+	}
+	if _, err := readServeTokens(tokens); err == nil || !strings.Contains(err.Error(), ":3: expected name:token") {
+		t.Fatalf("expected line error, got %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := Run([]string{"serve", "--addr", ":0", "--api-key", "test-key"}, &stdout, &stderr)
+	if exitCode != 2 || !strings.Contains(stderr.String(), "pass --tokens-file") {
+		t.Fatalf("expected addr validation error, got %d: %s", exitCode, stderr.String())
+	}
+}
@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qztseng/gplace"
+)
+
+// addressColumnPrefix names the per-type address component columns, e.g.
+// address_locality or address_postal_code.
+const addressColumnPrefix = "address_"
+
+var (
+	latLngType           = reflect.TypeOf(gplace.LatLng{})
+	moneyType            = reflect.TypeOf(gplace.Money{})
+	addressComponentType = reflect.TypeOf([]gplace.AddressComponent{})
+)
+
+// cellSpacer keeps each row on one line.
+var cellSpacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// Default columns for the tabular formats; --columns replaces them.
+var (
+	placeColumns    = []string{"place_id", "name", "address", "lat", "lng", "rating", "user_rating_count", "price_level", "types"}
+	detailsColumns  = []string{"place_id", "name", "address", "lat", "lng", "rating", "user_rating_count", "price_level", "primary_type", "phone", "website", "google_maps_uri"}
+	resolvedColumns = []string{"place_id", "name", "address", "lat", "lng", "types"}
+	routeColumns    = []string{"place_id", "name", "address", "lat", "lng", "rating", "user_rating_count", "distance_along_m", "distance_from_route_m", "detour_seconds", "detour_meters"}
+)
+
+// table is a flat view of results: one row per place, keyed by column name.
+type table struct {
+	columns []string
+	rows    []map[string]string
+}
+
+// newTable flattens rows, which must share one struct type. Nested structs
+// become prefix_field columns, a location becomes lat/lng, string lists are
+// joined with ";", address components become address_<type> columns and
+// other lists are dropped.
+func newTable[T any](rows []T, columns []string, defaults []string) (table, error) {
+	if len(columns) == 0 {
+		columns = defaults
+	}
+	known := map[string]bool{}
+	for _, name := range tableColumns(reflect.TypeOf((*T)(nil)).Elem(), "") {
+		known[name] = true
+	}
+	for _, name := range columns {
+		if !known[name] && !strings.HasPrefix(name, addressColumnPrefix) {
+			return table{}, gplace.ValidationError{Field: "columns", Message: fmt.Sprintf("unknown column %q", name)}
+		}
+	}
+
+	flat := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		cells := map[string]string{}
+		flattenValue(reflect.ValueOf(row), "", cells)
+		flat = append(flat, cells)
+	}
+	return table{columns: columns, rows: flat}, nil
+}
+
+// tableColumns lists the fixed columns of a struct type in field order.
+func tableColumns(typ reflect.Type, prefix string) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == latLngType:
+		return latLngColumns(prefix)
+	case typ == moneyType:
+		return []string{strings.TrimSuffix(prefix, "_")}
+	case typ.Kind() != reflect.Struct:
+		return nil
+	}
+
+	var columns []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, ok := columnName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			columns = append(columns, tableColumns(field.Type, prefix)...)
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case fieldType.Kind() == reflect.Struct:
+			columns = append(columns, tableColumns(fieldType, prefix+name+"_")...)
+		case fieldType.Kind() == reflect.Slice:
+			if fieldType.Elem().Kind() == reflect.String {
+				columns = append(columns, prefix+name)
+			}
+		default:
+			columns = append(columns, prefix+name)
+		}
+	}
+	return columns
+}
+
+// flattenValue writes the cells of value into cells, following the same
+// naming as tableColumns. Nil pointers leave their cells empty.
+func flattenValue(value reflect.Value, prefix string, cells map[string]string) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	switch {
+	case value.Type() == latLngType:
+		location := value.Interface().(gplace.LatLng)
+		columns := latLngColumns(prefix)
+		cells[columns[0]] = strconv.FormatFloat(location.Lat, 'f', -1, 64)
+		cells[columns[1]] = strconv.FormatFloat(location.Lng, 'f', -1, 64)
+		return
+	case value.Type() == moneyType:
+		money := value.Interface().(gplace.Money)
+		cells[strings.TrimSuffix(prefix, "_")] = formatMoney(&money)
+		return
+	case value.Kind() != reflect.Struct:
+		return
+	}
+
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, ok := columnName(field)
+		if !ok {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			flattenValue(fieldValue, prefix, cells)
+			continue
+		}
+		if field.Type == addressComponentType {
+			flattenAddress(fieldValue.Interface().([]gplace.AddressComponent), cells)
+			continue
+		}
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		switch fieldValue.Kind() {
+		case reflect.Struct:
+			flattenValue(fieldValue, prefix+name+"_", cells)
+		case reflect.Slice:
+			if fieldValue.Type().Elem().Kind() == reflect.String {
+				cells[prefix+name] = strings.Join(fieldValue.Interface().([]string), ";")
+			}
+		default:
+			cells[prefix+name] = formatCell(fieldValue)
+		}
+	}
+}
+
+// flattenAddress keeps the first long text for each component type.
+func flattenAddress(components []gplace.AddressComponent, cells map[string]string) {
+	for _, component := range components {
+		for _, kind := range component.Types {
+			column := addressColumnPrefix + kind
+			if _, ok := cells[column]; !ok {
+				cells[column] = component.LongText
+			}
+		}
+	}
+}
+
+// columnName returns the JSON name of a field; embedded structs without a
+// tag return "" so their fields are inlined.
+func columnName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" && !field.Anonymous {
+		name = field.Name
+	}
+	return name, true
+}
+
+// latLngColumns names a location's columns: lat/lng for the place location
+// itself, prefix_lat/prefix_lng for any other coordinate.
+func latLngColumns(prefix string) []string {
+	if prefix == "" || prefix == "location_" {
+		return []string{"lat", "lng"}
+	}
+	return []string{prefix + "lat", prefix + "lng"}
+}
+
+func formatCell(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
+
+func writeCSV(writer io.Writer, data table) error {
+	out := csv.NewWriter(writer)
+	if err := out.Write(data.columns); err != nil {
+		return err
+	}
+	record := make([]string, len(data.columns))
+	for _, row := range data.rows {
+		for i, column := range data.columns {
+			record[i] = row[column]
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeTSV writes tab-separated values without quoting; tabs and line breaks
+// inside cells become spaces so every row stays on one line.
+func writeTSV(writer io.Writer, data table) error {
+	var out strings.Builder
+	out.WriteString(strings.Join(data.columns, "\t"))
+	out.WriteByte('\n')
+	record := make([]string, len(data.columns))
+	for _, row := range data.rows {
+		for i, column := range data.columns {
+			record[i] = cellSpacer.Replace(row[column])
+		}
+		out.WriteString(strings.Join(record, "\t"))
+		out.WriteByte('\n')
+	}
+	_, err := io.WriteString(writer, out.String())
+	return err
+}
+
+// Layout of --format table.
+const (
+	tableGap            = 2
+	tableMinColumnWidth = 6
+)
+
+// renderTable aligns data in columns. When width is positive, the widest
+// columns are narrowed (down to tableMinColumnWidth) and their cells cut
+// with "…" until each line fits. Numeric columns are right-aligned.
+func renderTable(data table, width int) string {
+	if len(data.rows) == 0 {
+		return emptyResultsMessage
+	}
+	cells := make([][]string, len(data.rows))
+	widths := make([]int, len(data.columns))
+	numeric := make([]bool, len(data.columns))
+	for i, column := range data.columns {
+		widths[i] = utf8.RuneCountInString(column)
+		numeric[i] = true
+	}
+	for r, row := range data.rows {
+		cells[r] = make([]string, len(data.columns))
+		for i, column := range data.columns {
+			value := cellSpacer.Replace(row[column])
+			cells[r][i] = value
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+			if _, err := strconv.ParseFloat(value, 64); value != "" && err != nil {
+				numeric[i] = false
+			}
+		}
+	}
+
+	if width > 0 {
+		total := tableGap * (len(widths) - 1)
+		for _, w := range widths {
+			total += w
+		}
+		for total > width {
+			widest := -1
+			for i, w := range widths {
+				if w > tableMinColumnWidth && (widest < 0 || w > widths[widest]) {
+					widest = i
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			widths[widest]--
+			total--
+		}
+	}
+
+	var out strings.Builder
+	writeRow := func(values []string, alignRight bool) {
+		line := make([]string, len(values))
+		for i, value := range values {
+			value = fitCell(value, widths[i])
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value))
+			if alignRight && numeric[i] {
+				line[i] = padding + value
+			} else {
+				line[i] = value + padding
+			}
+		}
+		out.WriteString(strings.TrimRight(strings.Join(line, strings.Repeat(" ", tableGap)), " "))
+		out.WriteByte('\n')
+	}
+	writeRow(data.columns, false)
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+	}
+	writeRow(rule, false)
+	for _, row := range cells {
+		writeRow(row, true)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// fitCell cuts value to width runes, marking the cut with "…".
+func fitCell(value string, width int) string {
+	if utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:width-1]) + "…"
+}
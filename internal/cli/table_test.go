@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+func TestNewTableFlattensDetails(t *testing.T) {
+	rating := 4.5
+	beer := true
+	place := gplace.PlaceDetails{
+		PlaceID:    "abc",
+		Name:       "Cafe",
+		Location:   &gplace.LatLng{Lat: 47.6, Lng: -122.3},
+		Rating:     &rating,
+		Types:      []string{"cafe", "food"},
+		ServesBeer: &beer,
+		PriceRange: &gplace.PriceRange{StartPrice: &gplace.Money{CurrencyCode: "USD", Units: 10}},
+		AddressComponents: []gplace.AddressComponent{
+			{LongText: "Seattle", Types: []string{"locality", "political"}},
+			{LongText: "98101", Types: []string{"postal_code"}},
+		},
+	}
+
+	columns := []string{"place_id", "lat", "lng", "rating", "types", "serves_beer", "serves_wine", "price_range_start_price", "address_locality", "address_postal_code", "address_country"}
+	data, err := newTable([]gplace.PlaceDetails{place}, columns, detailsColumns)
+	if err != nil {
+		t.Fatalf("new table: %v", err)
+	}
+	row := data.rows[0]
+	expected := map[string]string{
+		"place_id":                "abc",
+		"lat":                     "47.6",
+		"lng":                     "-122.3",
+		"rating":                  "4.5",
+		"types":                   "cafe;food",
+		"serves_beer":             "true",
+		"serves_wine":             "",
+		"price_range_start_price": "$10",
+		"address_locality":        "Seattle",
+		"address_postal_code":     "98101",
+		"address_country":         "",
+	}
+	for column, value := range expected {
+		if row[column] != value {
+			t.Fatalf("column %s: expected %q, got %q", column, value, row[column])
+		}
+	}
+}
+
+func TestNewTableColumns(t *testing.T) {
+	data, err := newTable([]gplace.RoutePlace{}, nil, routeColumns)
+	if err != nil {
+		t.Fatalf("default columns: %v", err)
+	}
+	if strings.Join(data.columns, ",") != strings.Join(routeColumns, ",") {
+		t.Fatalf("unexpected columns: %v", data.columns)
+	}
+
+	_, err = newTable([]gplace.PlaceSummary{}, []string{"name", "ratting"}, placeColumns)
+	if err == nil || !strings.Contains(err.Error(), "ratting") {
+		t.Fatalf("expected unknown column error, got %v", err)
+	}
+	if _, ok := err.(gplace.ValidationError); !ok {
+		t.Fatalf("expected validation error, got %T", err)
+	}
+}
+
+func TestWriteTSVFlattensLineBreaks(t *testing.T) {
+	var out bytes.Buffer
+	data := table{columns: []string{"name", "address"}, rows: []map[string]string{{"name": "A\tB", "address": "1 Main\nSt"}}}
+	if err := writeTSV(&out, data); err != nil {
+		t.Fatalf("write tsv: %v", err)
+	}
+	if out.String() != "name\taddress\nA B\t1 Main St\n" {
+		t.Fatalf("unexpected tsv: %q", out.String())
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	data := table{
+		columns: []string{"name", "rating"},
+		rows: []map[string]string{
+			{"name": "Cafe", "rating": "4.5"},
+			{"name": "Long Name Bakery", "rating": "10"},
+		},
+	}
+	expected := "name              rating\n" +
+		"----------------  ------\n" +
+		"Cafe                 4.5\n" +
+		"Long Name Bakery      10"
+	if out := renderTable(data, 0); out != expected {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+
+	narrow := renderTable(data, 16)
+	for _, line := range strings.Split(narrow, "\n") {
+		if len([]rune(line)) > 16 {
+			t.Fatalf("line wider than 16: %q", line)
+		}
+	}
+	if !strings.Contains(narrow, "Long Na…") {
+		t.Fatalf("expected truncated name:\n%s", narrow)
+	}
+	if renderTable(table{columns: []string{"name"}}, 0) != emptyResultsMessage {
+		t.Fatalf("expected empty message")
+	}
+}
+
+func TestRunSearchTable(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"},"rating":4.5}]}`))
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--format", "table",
+		"--columns", "name,rating",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if stdout.String() != "name  rating\n----  ------\nCafe     4.5\n" {
+		t.Fatalf("unexpected output: %q", stdout.String())
+	}
+}
+
+func TestRunSearchCSV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe, Bar"},"rating":4.5,"location":{"latitude":1.5,"longitude":2}}]}`))
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--format", "csv",
+		"--columns", "place_id,name,rating,lat,lng",
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 2 || strings.Join(records[0], ",") != "place_id,name,rating,lat,lng" {
+		t.Fatalf("unexpected csv: %v", records)
+	}
+	if strings.Join(records[1], "|") != "abc|Cafe, Bar|4.5|1.5|2" {
+		t.Fatalf("unexpected row: %v", records[1])
+	}
+}
+
+func TestRunColumnsRequiresTabularFormat(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--columns", "name",
+		"--api-key", "test-key",
+	}, &stdout, &stderr)
+
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "columns") {
+		t.Fatalf("unexpected stderr: %s", stderr.String())
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/qztseng/gplace"
+)
+
+// formatTemplate is selected by --template/--template-file rather than
+// --format.
+const formatTemplate = "template"
+
+// templateFuncs are the helpers available to --template, built on the text
+// renderers so custom layouts format values the same way.
+var templateFuncs = template.FuncMap{
+	"stars": func(rating any) string {
+		value, ok := templateNumber(rating)
+		if !ok {
+			return ""
+		}
+		return formatStars(value)
+	},
+	"price": func(value any) string {
+		if priceRange, ok := value.(*gplace.PriceRange); ok {
+			return formatPriceRange(priceRange)
+		}
+		level, ok := templateNumber(value)
+		if !ok {
+			return ""
+		}
+		return formatPriceLevel(int(level))
+	},
+	"money": func(value any) string {
+		switch money := value.(type) {
+		case *gplace.Money:
+			return formatMoney(money)
+		case gplace.Money:
+			return formatMoney(&money)
+		default:
+			return ""
+		}
+	},
+	"truncate": func(length int, value string) string {
+		return truncateText(value, length)
+	},
+	"distance": func(meters any) string {
+		value, ok := templateNumber(meters)
+		if !ok {
+			return ""
+		}
+		return formatDistance(value)
+	},
+}
+
+// loadTemplate parses --template or the contents of --template-file; it
+// returns nil when neither is set. One trailing newline is dropped because
+// each result is already written on its own line.
+func loadTemplate(text string, path string) (*template.Template, error) {
+	if text != "" && path != "" {
+		return nil, gplace.ValidationError{Field: "template", Message: "use either --template or --template-file"}
+	}
+	if path != "" {
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("gplace: read template: %w", err)
+		}
+		text = string(payload)
+	}
+	if text == "" {
+		return nil, nil
+	}
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, gplace.ValidationError{Field: "template", Message: err.Error()}
+	}
+	return tmpl, nil
+}
+
+// writeTemplate executes the template once per item, one item per line.
+func writeTemplate[T any](app *App, items []T) error {
+	var out bytes.Buffer
+	for _, item := range items {
+		out.Reset()
+		if err := app.template.Execute(&out, item); err != nil {
+			return fmt.Errorf("gplace: template: %w", err)
+		}
+		out.WriteByte('\n')
+		if _, err := app.out.Write(out.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateNumber reads ints, floats and pointers to them; nil pointers and
+// other values report false.
+func templateNumber(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qztseng/gplace"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := loadTemplate(`{{stars .Rating}}|{{price .PriceLevel}}|{{price .PriceRange}}|{{money .PriceRange.StartPrice}}|{{.Name | truncate 4}}|{{stars .UserRatingCount}}`, "")
+	if err != nil {
+		t.Fatalf("load template: %v", err)
+	}
+	rating := 3.6
+	level := 3
+	var out bytes.Buffer
+	app := &App{out: &out, template: tmpl}
+	err = writeTemplate(app, []gplace.PlaceDetails{{
+		Name:       "Bakery",
+		Rating:     &rating,
+		PriceLevel: &level,
+		PriceRange: &gplace.PriceRange{StartPrice: &gplace.Money{CurrencyCode: "EUR", Units: 5}, EndPrice: &gplace.Money{CurrencyCode: "EUR", Units: 9}},
+	}})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() != "★★★★☆|$$$|€5–€9|€5|Bake...|\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+
+	tmpl, err = loadTemplate(`{{distance .DistanceAlongM}} {{distance .DetourMeters}}`, "")
+	if err != nil {
+		t.Fatalf("load template: %v", err)
+	}
+	out.Reset()
+	along := 12345.0
+	detour := 800
+	app.template = tmpl
+	if err := writeTemplate(app, []gplace.RoutePlace{{DistanceAlongM: &along, DetourMeters: &detour}, {}}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() != "12.3 km 800 m\n \n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	if _, err := loadTemplate("{{.Name", ""); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if _, err := loadTemplate("x", "y"); err == nil {
+		t.Fatalf("expected error for both template flags")
+	}
+	if tmpl, err := loadTemplate("", ""); tmpl != nil || err != nil {
+		t.Fatalf("expected no template, got %v %v", tmpl, err)
+	}
+}
+
+func TestRunSearchTemplateFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"places":[{"id":"a","displayName":{"text":"Cafe"},"rating":4.5},{"id":"b","displayName":{"text":"Bar"}}]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "line.tmpl")
+	if err := os.WriteFile(path, []byte("{{.PlaceID}}: {{.Name}}{{with .Rating}} ({{.}}){{end}}\n"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{
+		"search",
+		"coffee",
+		"--template-file", path,
+		"--api-key", "test-key",
+		"--base-url", server.URL,
+	}, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", exitCode, stderr.String())
+	}
+	if stdout.String() != "a: Cafe (4.5)\nb: Bar\n" {
+		t.Fatalf("unexpected output: %q", stdout.String())
+	}
+}
+
+func TestRunTemplateWithFormat(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := Run([]string{"search", "coffee", "--template", "{{.Name}}", "--json", "--api-key", "test-key"}, &stdout, &stderr)
+	if exitCode != 2 || !strings.Contains(stderr.String(), "template") {
+		t.Fatalf("expected template validation error, got %d (%s)", exitCode, stderr.String())
+	}
+}
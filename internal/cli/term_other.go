@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package cli
+
+import "errors"
+
+func makeRaw(_ int) (func() error, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func disableEcho(_ int) (func() error, error) {
+	return nil, errors.New("terminal echo control is not supported on this platform")
+}
+
+func terminalWidth(_ int) (int, error) {
+	return 0, errors.New("terminal size is not supported on this platform")
+}
@@ -0,0 +1,71 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package cli
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw switches the terminal on fd to raw mode and returns a restore func.
+func makeRaw(fd int) (func() error, error) {
+	var original syscall.Termios
+	if err := termiosIoctl(fd, ioctlGetTermios, &original); err != nil {
+		return nil, err
+	}
+
+	raw := original
+	// Read single keys without echo or line editing; keep output processing so
+	// "\n" still returns the carriage. Reads return empty after 0.1s without
+	// input so a reader can notice it should stop.
+	raw.Iflag &^= syscall.ICRNL | syscall.INLCR | syscall.IXON | syscall.ISTRIP
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 0
+	raw.Cc[syscall.VTIME] = 1
+	if err := termiosIoctl(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return termiosIoctl(fd, ioctlSetTermios, &original)
+	}, nil
+}
+
+// disableEcho stops the terminal on fd from echoing input while keeping line
+// editing, for reading secrets, and returns a restore func.
+func disableEcho(fd int) (func() error, error) {
+	var original syscall.Termios
+	if err := termiosIoctl(fd, ioctlGetTermios, &original); err != nil {
+		return nil, err
+	}
+
+	silent := original
+	silent.Lflag &^= syscall.ECHO
+	if err := termiosIoctl(fd, ioctlSetTermios, &silent); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return termiosIoctl(fd, ioctlSetTermios, &original)
+	}, nil
+}
+
+// terminalWidth returns the column count of the terminal on fd.
+func terminalWidth(fd int) (int, error) {
+	var size struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(size.cols), nil
+}
+
+func termiosIoctl(fd int, request uintptr, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+
+	"github.com/qztseng/gplace"
+)
+
+// agentTool is a gplace operation offered to AI agents as a tool. Its input
+// schema is derived from the library request type, so it cannot drift from
+// what the client accepts.
+type agentTool struct {
+	name        string
+	title       string
+	description string
+	request     reflect.Type
+	call        func(ctx context.Context, client *gplace.Client, arguments []byte) (any, error)
+}
+
+// agentTools lists the tools in the order agents see them.
+var agentTools = []agentTool{
+	newAgentTool("search_places", "Search places",
+		"Search places by text query, e.g. \"ramen in Shibuya\", with optional rating, price, type and open-now filters. Returns place summaries with IDs for place_details.",
+		(*gplace.Client).Search),
+	newAgentTool("nearby_places", "Nearby places",
+		"List places of the given types inside a circle around coordinates.",
+		(*gplace.Client).NearbySearch),
+	newAgentTool("place_details", "Place details",
+		"Fetch full details of one place by ID: hours, contact, amenities, summaries and optionally reviews.",
+		(*gplace.Client).DetailsWithOptions),
+	newAgentTool("autocomplete_places", "Autocomplete",
+		"Complete partial text to place and query suggestions.",
+		(*gplace.Client).Autocomplete),
+	newAgentTool("resolve_location", "Resolve location",
+		"Turn an address, landmark or area name into candidate places with coordinates, e.g. to build a location_bias.",
+		(*gplace.Client).Resolve),
+	newAgentTool("route_search", "Search along a route",
+		"Find places along a route between an origin and a destination, e.g. gas stations between two cities.",
+		(*gplace.Client).Route),
+}
+
+func newAgentTool[Req any, Resp any](
+	name, title, description string,
+	call func(*gplace.Client, context.Context, Req) (Resp, error),
+) agentTool {
+	return agentTool{
+		name:        name,
+		title:       title,
+		description: description,
+		request:     reflect.TypeFor[Req](),
+		call: func(ctx context.Context, client *gplace.Client, arguments []byte) (any, error) {
+			if len(bytes.TrimSpace(arguments)) == 0 || string(bytes.TrimSpace(arguments)) == "null" {
+				arguments = []byte("{}")
+			}
+			var req Req
+			if err := decodeRequest(bytes.NewReader(arguments), &req); err != nil {
+				return nil, err
+			}
+			return call(client, ctx, req)
+		},
+	}
+}
+
+// inputSchema is the JSON Schema of the tool's arguments.
+func (t agentTool) inputSchema() *jsonSchema {
+	return schemaFor(t.request)
+}
+
+func findAgentTool(name string) (agentTool, bool) {
+	for _, tool := range agentTools {
+		if tool.name == name {
+			return tool, true
+		}
+	}
+	return agentTool{}, false
+}
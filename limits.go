@@ -9,4 +9,5 @@ const (
 	maxAutocompleteLimit     = 20
 	defaultNearbyLimit       = 10
 	maxNearbyLimit           = 20
+	defaultMatchLimit        = 5
 )
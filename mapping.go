@@ -3,6 +3,7 @@ package gplace
 import (
 	"strconv"
 	"strings"
+	"time"
 )
 
 func mapPriceRange(payload *priceRangePayload) *PriceRange {
@@ -125,6 +126,36 @@ func mapVisitDate(date *visitDatePayload) *ReviewVisitDate {
 	}
 }
 
+func mapRoutingSummaries(payload []routingSummaryPayload) []RoutingSummary {
+	if len(payload) == 0 {
+		return nil
+	}
+	mapped := make([]RoutingSummary, 0, len(payload))
+	for _, summary := range payload {
+		legs := make([]RoutingLeg, 0, len(summary.Legs))
+		for _, leg := range summary.Legs {
+			legs = append(legs, RoutingLeg{
+				DurationSeconds: durationSeconds(leg.Duration),
+				DistanceMeters:  leg.DistanceMeters,
+			})
+		}
+		mapped = append(mapped, RoutingSummary{Legs: legs, DirectionsURI: summary.DirectionsURI})
+	}
+	return mapped
+}
+
+// durationSeconds parses protobuf JSON durations such as "754s".
+func durationSeconds(value string) int {
+	if value == "" {
+		return 0
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return int(parsed.Round(time.Second) / time.Second)
+}
+
 func mapLatLng(loc *location) *LatLng {
 	if loc == nil {
 		return nil
@@ -0,0 +1,250 @@
+package gplace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/qztseng/gplace/geo"
+)
+
+const (
+	// matchBiasRadiusM biases the candidate search around the reference
+	// location without excluding places geocoded slightly elsewhere.
+	matchBiasRadiusM = 5000
+	// matchDistanceScaleM is the distance at which the proximity score has
+	// dropped to 1/e.
+	matchDistanceScaleM = 1000
+	matchNameWeight     = 0.6
+)
+
+// MatchRequest describes a business to find by name, with an address or a
+// location to tell branches apart.
+type MatchRequest struct {
+	Name     string  `json:"name"`
+	Address  string  `json:"address,omitempty"`
+	Location *LatLng `json:"location,omitempty"`
+	// Limit is the number of search candidates to score.
+	Limit    int    `json:"limit,omitempty"`
+	Language string `json:"language,omitempty"`
+	Region   string `json:"region,omitempty"`
+}
+
+// MatchResponse lists scored candidates, best first.
+type MatchResponse struct {
+	// Best is the highest-confidence candidate; nil when nothing was found.
+	Best       *MatchCandidate  `json:"best,omitempty"`
+	Candidates []MatchCandidate `json:"candidates"`
+	// Reference is the location distances were measured from: the request
+	// location, or the resolved address.
+	Reference *LatLng `json:"reference,omitempty"`
+}
+
+// MatchCandidate is a search result scored against the request.
+type MatchCandidate struct {
+	Place PlaceSummary `json:"place"`
+	// NameScore is the name similarity, from 0 to 1.
+	NameScore float64 `json:"name_score"`
+	// DistanceMeters is measured from MatchResponse.Reference.
+	DistanceMeters *float64 `json:"distance_meters,omitempty"`
+	// Confidence combines name similarity and proximity, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+}
+
+// Match finds the place that best matches a business name and address. The
+// address is resolved to a reference location, candidates come from a text
+// search biased around it, and each candidate is scored by name similarity
+// and distance. Without an address or location only names are compared.
+func (c *Client) Match(ctx context.Context, req MatchRequest) (MatchResponse, error) {
+	req = applyMatchDefaults(req)
+	if err := validateMatchRequest(req); err != nil {
+		return MatchResponse{}, err
+	}
+
+	reference := req.Location
+	address := strings.TrimSpace(req.Address)
+	if reference == nil && address != "" {
+		resolved, err := c.Resolve(ctx, LocationResolveRequest{
+			LocationText: address,
+			Limit:        1,
+			Language:     req.Language,
+			Region:       req.Region,
+		})
+		if err != nil {
+			return MatchResponse{}, err
+		}
+		if len(resolved.Results) > 0 {
+			reference = resolved.Results[0].Location
+		}
+	}
+
+	search := SearchRequest{
+		Query:    strings.TrimSpace(req.Name + " " + address),
+		Limit:    req.Limit,
+		Language: req.Language,
+		Region:   req.Region,
+	}
+	if reference != nil {
+		search.LocationBias = &LocationBias{Lat: reference.Lat, Lng: reference.Lng, RadiusM: matchBiasRadiusM}
+	}
+	results, err := c.Search(ctx, search)
+	if err != nil {
+		return MatchResponse{}, err
+	}
+
+	response := MatchResponse{
+		Candidates: scoreMatches(req.Name, reference, results.Results),
+		Reference:  reference,
+	}
+	if len(response.Candidates) > 0 {
+		response.Best = &response.Candidates[0]
+	}
+	return response, nil
+}
+
+// scoreMatches scores places against name and reference, best first.
+func scoreMatches(name string, reference *LatLng, places []PlaceSummary) []MatchCandidate {
+	candidates := make([]MatchCandidate, 0, len(places))
+	for _, place := range places {
+		candidate := MatchCandidate{
+			Place:     place,
+			NameScore: nameSimilarity(name, place.Name),
+		}
+		candidate.Confidence = candidate.NameScore
+		if reference != nil {
+			// Without a location the candidate gets no proximity credit.
+			proximity := 0.0
+			if place.Location != nil {
+				distance := geo.Distance(*reference, *place.Location)
+				candidate.DistanceMeters = &distance
+				proximity = math.Exp(-distance / matchDistanceScaleM)
+			}
+			candidate.Confidence = matchNameWeight*candidate.NameScore + (1-matchNameWeight)*proximity
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+	return candidates
+}
+
+func applyMatchDefaults(req MatchRequest) MatchRequest {
+	if req.Limit == 0 {
+		req.Limit = defaultMatchLimit
+	}
+	return req
+}
+
+func validateMatchRequest(req MatchRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return ValidationError{Field: "name", Message: "required"}
+	}
+	if req.Limit < 1 || req.Limit > maxSearchLimit {
+		return ValidationError{Field: "limit", Message: fmt.Sprintf("must be 1-%d", maxSearchLimit)}
+	}
+	if req.Location != nil {
+		if req.Location.Lat < -90 || req.Location.Lat > 90 {
+			return ValidationError{Field: "location.lat", Message: "must be -90..90"}
+		}
+		if req.Location.Lng < -180 || req.Location.Lng > 180 {
+			return ValidationError{Field: "location.lng", Message: "must be -180..180"}
+		}
+	}
+	return nil
+}
+
+// nameStopWords are dropped before names are compared; they rarely tell two
+// businesses apart.
+var nameStopWords = map[string]bool{
+	"the": true, "and": true, "inc": true, "llc": true, "ltd": true,
+	"co": true, "corp": true, "company": true, "gmbh": true,
+}
+
+// nameSimilarity compares business names from 0 (unrelated) to 1 (same
+// normalized name). It is the larger of the character-bigram Dice
+// coefficient and a slightly discounted token containment, so "Blue Bottle"
+// still scores high against "Blue Bottle Coffee".
+func nameSimilarity(a, b string) float64 {
+	tokensA := nameTokens(a)
+	tokensB := nameTokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+	joinedA := strings.Join(tokensA, " ")
+	joinedB := strings.Join(tokensB, " ")
+	if joinedA == joinedB {
+		return 1
+	}
+	return math.Max(bigramDice(joinedA, joinedB), 0.9*tokenContainment(tokensA, tokensB))
+}
+
+// nameTokens lowercases a name, splits it on anything that is not a letter
+// or digit and drops stop words.
+func nameTokens(name string) []string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := fields[:0]
+	for _, field := range fields {
+		if !nameStopWords[field] {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+func bigramDice(a, b string) float64 {
+	bigramsA := runeBigrams(a)
+	bigramsB := runeBigrams(b)
+	if len(bigramsA) == 0 || len(bigramsB) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(bigramsA))
+	for _, bigram := range bigramsA {
+		counts[bigram]++
+	}
+	shared := 0
+	for _, bigram := range bigramsB {
+		if counts[bigram] > 0 {
+			counts[bigram]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(bigramsA)+len(bigramsB))
+}
+
+func runeBigrams(value string) []string {
+	runes := []rune(value)
+	if len(runes) < 2 {
+		return []string{value}
+	}
+	bigrams := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		bigrams = append(bigrams, string(runes[i:i+2]))
+	}
+	return bigrams
+}
+
+// tokenContainment is the share of the shorter name's tokens found in the
+// other name.
+func tokenContainment(a, b []string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	present := make(map[string]bool, len(b))
+	for _, token := range b {
+		present[token] = true
+	}
+	found := 0
+	for _, token := range a {
+		if present[token] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(a))
+}
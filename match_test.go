@@ -0,0 +1,97 @@
+package gplace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"Blue Bottle Coffee", "blue bottle coffee", 1, 1},
+		{"The Coffee Co.", "Coffee", 1, 1},
+		{"Ben & Jerry's", "Ben and Jerrys", 0.7, 1},
+		{"Blue Bottle", "Blue Bottle Coffee", 0.9, 1},
+		{"Blue Bottle Coffee", "Starbucks", 0, 0.3},
+		{"", "Cafe", 0, 0},
+	}
+	for _, tc := range cases {
+		score := nameSimilarity(tc.a, tc.b)
+		if score < tc.min || score > tc.max {
+			t.Fatalf("nameSimilarity(%q, %q) = %.3f, want %.2f-%.2f", tc.a, tc.b, score, tc.min, tc.max)
+		}
+	}
+}
+
+func TestScoreMatchesPrefersNearbyBranch(t *testing.T) {
+	reference := &LatLng{Lat: 47.6, Lng: -122.3}
+	candidates := scoreMatches("Blue Bottle Coffee", reference, []PlaceSummary{
+		{PlaceID: "far", Name: "Blue Bottle Coffee", Location: &LatLng{Lat: 47.7, Lng: -122.3}},
+		{PlaceID: "other", Name: "Starbucks", Location: &LatLng{Lat: 47.6, Lng: -122.3}},
+		{PlaceID: "near", Name: "Blue Bottle Coffee", Location: &LatLng{Lat: 47.601, Lng: -122.3}},
+		{PlaceID: "unknown", Name: "Blue Bottle Coffee"},
+	})
+	if candidates[0].Place.PlaceID != "near" || candidates[0].Confidence < 0.9 {
+		t.Fatalf("expected near branch first: %#v", candidates[0])
+	}
+	if candidates[0].DistanceMeters == nil || *candidates[0].DistanceMeters > 200 {
+		t.Fatalf("unexpected distance: %v", candidates[0].DistanceMeters)
+	}
+	if candidates[3].Place.PlaceID != "other" {
+		t.Fatalf("expected unrelated name last: %#v", candidates)
+	}
+
+	nameOnly := scoreMatches("Blue Bottle", nil, []PlaceSummary{{Name: "Blue Bottle"}})
+	if nameOnly[0].Confidence != 1 || nameOnly[0].DistanceMeters != nil {
+		t.Fatalf("expected name-only confidence: %#v", nameOnly[0])
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if len(bodies) == 1 {
+			_, _ = w.Write([]byte(`{"places":[{"id":"addr","location":{"latitude":47.6,"longitude":-122.3}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"places":[
+			{"id":"a","displayName":{"text":"Cafe Uno"},"location":{"latitude":47.6,"longitude":-122.3}},
+			{"id":"b","displayName":{"text":"Cafe Dos"},"location":{"latitude":47.6,"longitude":-122.3}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL})
+	response, err := client.Match(context.Background(), MatchRequest{Name: "Cafe Dos", Address: "1 Main St"})
+	if err != nil {
+		t.Fatalf("match error: %v", err)
+	}
+	if len(bodies) != 2 || bodies[0]["textQuery"] != "1 Main St" || bodies[1]["textQuery"] != "Cafe Dos 1 Main St" {
+		t.Fatalf("unexpected requests: %#v", bodies)
+	}
+	if _, ok := bodies[1]["locationBias"]; !ok {
+		t.Fatalf("expected location bias: %#v", bodies[1])
+	}
+	if response.Best == nil || response.Best.Place.PlaceID != "b" || response.Reference == nil {
+		t.Fatalf("unexpected match: %#v", response)
+	}
+}
+
+func TestMatchValidation(t *testing.T) {
+	client := NewClient(Options{APIKey: "test-key"})
+	if _, err := client.Match(context.Background(), MatchRequest{}); err == nil {
+		t.Fatalf("expected name validation error")
+	}
+	if _, err := client.Match(context.Background(), MatchRequest{Name: "x", Location: &LatLng{Lat: 91}}); err == nil {
+		t.Fatalf("expected location validation error")
+	}
+}
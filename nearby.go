@@ -73,5 +73,8 @@ func validateNearbyRequest(req NearbySearchRequest) error {
 	if req.Limit < 1 || req.Limit > maxNearbyLimit {
 		return ValidationError{Field: "limit", Message: fmt.Sprintf("must be 1-%d", maxNearbyLimit)}
 	}
-	return nil
+	if err := validatePlaceTypes("included_types", req.IncludedTypes); err != nil {
+		return err
+	}
+	return validatePlaceTypes("excluded_types", req.ExcludedTypes)
 }
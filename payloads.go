@@ -1,8 +1,19 @@
 package gplace
 
 type searchResponse struct {
-	Places        []placeItem `json:"places"`
-	NextPageToken string      `json:"nextPageToken"`
+	Places           []placeItem             `json:"places"`
+	NextPageToken    string                  `json:"nextPageToken"`
+	RoutingSummaries []routingSummaryPayload `json:"routingSummaries,omitempty"`
+}
+
+type routingSummaryPayload struct {
+	Legs          []routingLegPayload `json:"legs,omitempty"`
+	DirectionsURI string              `json:"directionsUri,omitempty"`
+}
+
+type routingLegPayload struct {
+	Duration       string `json:"duration,omitempty"` // e.g. "754s"
+	DistanceMeters int    `json:"distanceMeters,omitempty"`
 }
 
 type placeItem struct {
@@ -0,0 +1,191 @@
+package gplace
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PlaceTypeUsage says where a place type may be used.
+type PlaceTypeUsage string
+
+const (
+	// PlaceTypeUsageAny types (Table A) filter requests and appear in
+	// responses.
+	PlaceTypeUsageAny PlaceTypeUsage = "any"
+	// PlaceTypeUsageResponse types (Table B) only appear in responses.
+	PlaceTypeUsageResponse PlaceTypeUsage = "response"
+)
+
+// maxTypeSuggestions caps the "did you mean" list.
+const maxTypeSuggestions = 3
+
+// PlaceType is one entry of the place type catalog.
+type PlaceType struct {
+	Name     string         `json:"type"`
+	Category string         `json:"category"`
+	Usage    PlaceTypeUsage `json:"usage"`
+}
+
+//go:embed placetypes.tsv
+var placeTypesTSV string
+
+// placeTypeCatalog parses the embedded catalog once, sorted by category and
+// name.
+var placeTypeCatalog = sync.OnceValue(func() []PlaceType {
+	var catalog []PlaceType
+	for _, line := range strings.Split(placeTypesTSV, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			panic(fmt.Sprintf("gplace: malformed place type line %q", line))
+		}
+		catalog = append(catalog, PlaceType{Name: fields[0], Category: fields[1], Usage: PlaceTypeUsage(fields[2])})
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Category != catalog[j].Category {
+			return catalog[i].Category < catalog[j].Category
+		}
+		return catalog[i].Name < catalog[j].Name
+	})
+	return catalog
+})
+
+// PlaceTypes returns the place type catalog, sorted by category and name.
+func PlaceTypes() []PlaceType {
+	return append([]PlaceType(nil), placeTypeCatalog()...)
+}
+
+// PlaceTypeCategories returns the catalog's categories in order.
+func PlaceTypeCategories() []string {
+	var categories []string
+	for _, placeType := range placeTypeCatalog() {
+		if len(categories) == 0 || categories[len(categories)-1] != placeType.Category {
+			categories = append(categories, placeType.Category)
+		}
+	}
+	return categories
+}
+
+// LookupPlaceType finds a place type by its exact name.
+func LookupPlaceType(name string) (PlaceType, bool) {
+	for _, placeType := range placeTypeCatalog() {
+		if placeType.Name == name {
+			return placeType, true
+		}
+	}
+	return PlaceType{}, false
+}
+
+// FindPlaceTypes returns the types in category (all when empty) whose name
+// contains every word of query, e.g. "ramen" or "japanese restaurant".
+func FindPlaceTypes(query string, category string) []PlaceType {
+	words := strings.Fields(strings.ToLower(strings.NewReplacer("_", " ", "-", " ").Replace(query)))
+	var found []PlaceType
+	for _, placeType := range placeTypeCatalog() {
+		if category != "" && placeType.Category != category {
+			continue
+		}
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(placeType.Name, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			found = append(found, placeType)
+		}
+	}
+	return found
+}
+
+// SuggestPlaceTypes returns up to three filterable types close to name:
+// its normalized form ("Coffee Shop" → coffee_shop), near misspellings, then
+// types containing it as a word ("ramen" → ramen_restaurant).
+func SuggestPlaceTypes(name string) []string {
+	name = normalizePlaceType(name)
+	if name == "" {
+		return nil
+	}
+	maxEdits := max(1, len(name)/4)
+	type candidate struct {
+		name  string
+		score int
+	}
+	var candidates []candidate
+	for _, placeType := range placeTypeCatalog() {
+		if placeType.Usage != PlaceTypeUsageAny {
+			continue
+		}
+		if placeType.Name == name {
+			return []string{name}
+		}
+		if distance := editDistance(name, placeType.Name); distance <= maxEdits {
+			candidates = append(candidates, candidate{placeType.Name, distance})
+		} else if strings.Contains("_"+placeType.Name+"_", "_"+name+"_") {
+			candidates = append(candidates, candidate{placeType.Name, maxEdits + 1 + len(placeType.Name)})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	suggestions := make([]string, 0, maxTypeSuggestions)
+	for _, candidate := range candidates {
+		if len(suggestions) == maxTypeSuggestions {
+			break
+		}
+		suggestions = append(suggestions, candidate.name)
+	}
+	return suggestions
+}
+
+// validatePlaceTypes checks request type filters against the catalog.
+func validatePlaceTypes(field string, types []string) error {
+	for _, name := range types {
+		placeType, ok := LookupPlaceType(name)
+		switch {
+		case !ok:
+			message := fmt.Sprintf("unknown place type %q", name)
+			if suggestions := SuggestPlaceTypes(name); len(suggestions) > 0 {
+				message += "; did you mean " + strings.Join(suggestions, ", ") + "?"
+			}
+			return ValidationError{Field: field, Message: message}
+		case placeType.Usage == PlaceTypeUsageResponse:
+			return ValidationError{Field: field, Message: fmt.Sprintf("place type %q only appears in responses", name)}
+		}
+	}
+	return nil
+}
+
+func normalizePlaceType(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.NewReplacer("_", " ", "-", " ").Replace(name))), "_")
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,132 @@
+package gplace
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlaceTypeCatalog(t *testing.T) {
+	catalog := PlaceTypes()
+	seen := map[string]bool{}
+	for _, placeType := range catalog {
+		if seen[placeType.Name] {
+			t.Fatalf("duplicate place type %s", placeType.Name)
+		}
+		seen[placeType.Name] = true
+		switch placeType.Usage {
+		case PlaceTypeUsageAny, PlaceTypeUsageResponse:
+		default:
+			t.Fatalf("%s has unknown usage %q", placeType.Name, placeType.Usage)
+		}
+	}
+	for name, usage := range map[string]PlaceTypeUsage{
+		"ramen_restaurant":  PlaceTypeUsageAny,
+		"locality":          PlaceTypeUsageAny,
+		"point_of_interest": PlaceTypeUsageResponse,
+	} {
+		if placeType, ok := LookupPlaceType(name); !ok || placeType.Usage != usage {
+			t.Fatalf("LookupPlaceType(%s) = %+v, %v", name, placeType, ok)
+		}
+	}
+
+	categories := PlaceTypeCategories()
+	if len(categories) < 10 || categories[0] > categories[1] {
+		t.Fatalf("unexpected categories %v", categories)
+	}
+	catalog[0].Name = "changed"
+	if PlaceTypes()[0].Name == "changed" {
+		t.Fatal("PlaceTypes returned the shared catalog")
+	}
+}
+
+func TestFindPlaceTypes(t *testing.T) {
+	var names []string
+	for _, placeType := range FindPlaceTypes("Ramen", "food") {
+		names = append(names, placeType.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"ramen_restaurant"}) {
+		t.Fatalf("unexpected ramen types %v", names)
+	}
+	if found := FindPlaceTypes("japanese restaurant", ""); len(found) != 1 || found[0].Name != "japanese_restaurant" {
+		t.Fatalf("expected every word to match, got %v", found)
+	}
+	if found := FindPlaceTypes("ramen", "shopping"); len(found) != 0 {
+		t.Fatalf("expected category filter, got %v", found)
+	}
+}
+
+func TestSuggestPlaceTypes(t *testing.T) {
+	cases := map[string]string{
+		"resturant":   "restaurant",
+		"Coffee Shop": "coffee_shop",
+		"ramen":       "ramen_restaurant",
+		"gas-station": "gas_station",
+	}
+	for input, want := range cases {
+		if got := SuggestPlaceTypes(input); len(got) == 0 || got[0] != want {
+			t.Fatalf("SuggestPlaceTypes(%q) = %v, want %s first", input, got, want)
+		}
+	}
+	if got := SuggestPlaceTypes("zzzzzz"); len(got) != 0 {
+		t.Fatalf("expected no suggestions, got %v", got)
+	}
+	for _, name := range SuggestPlaceTypes("restaurnt") {
+		if placeType, _ := LookupPlaceType(name); placeType.Usage != PlaceTypeUsageAny {
+			t.Fatalf("suggested unusable type %s", name)
+		}
+	}
+}
+
+func TestPlaceTypeValidation(t *testing.T) {
+	client := NewClient(Options{APIKey: "test-key", BaseURL: "http://127.0.0.1:0"})
+	cases := []struct {
+		call    func() error
+		field   string
+		message string
+	}{
+		{func() error {
+			_, err := client.Search(context.Background(), SearchRequest{Query: "noodles", Filters: &Filters{Types: []string{"ramen_resturant"}}})
+			return err
+		}, "filters.types", "did you mean ramen_restaurant"},
+		{func() error {
+			_, err := client.NearbySearch(context.Background(), NearbySearchRequest{
+				LocationRestriction: &LocationBias{Lat: 35.66, Lng: 139.7, RadiusM: 500},
+				IncludedTypes:       []string{"point_of_interest"},
+			})
+			return err
+		}, "included_types", "only appears in responses"},
+		{func() error {
+			_, err := client.NearbySearch(context.Background(), NearbySearchRequest{
+				LocationRestriction: &LocationBias{Lat: 35.66, Lng: 139.7, RadiusM: 500},
+				ExcludedTypes:       []string{"sublocality"},
+			})
+			return err
+		}, "excluded_types", "only appears in responses"},
+	}
+	for _, tc := range cases {
+		var validation ValidationError
+		if err := tc.call(); !errors.As(err, &validation) || validation.Field != tc.field || !strings.Contains(validation.Message, tc.message) {
+			t.Fatalf("expected %s error containing %q, got %v", tc.field, tc.message, err)
+		}
+	}
+}
+
+func TestPlaceTypeValidationAcceptsGeographicTypes(t *testing.T) {
+	for _, name := range []string{"administrative_area_level_1", "administrative_area_level_2", "country", "locality", "postal_code", "school_district"} {
+		search := applySearchDefaults(SearchRequest{Query: "parks", Filters: &Filters{Types: []string{name}}})
+		if err := validateSearchRequest(search); err != nil {
+			t.Fatalf("search with %s: %v", name, err)
+		}
+		nearby := applyNearbyDefaults(NearbySearchRequest{
+			LocationRestriction: &LocationBias{Lat: 35.66, Lng: 139.7, RadiusM: 500},
+			IncludedTypes:       []string{name},
+			ExcludedTypes:       []string{name},
+		})
+		if err := validateNearbyRequest(nearby); err != nil {
+			t.Fatalf("nearby with %s: %v", name, err)
+		}
+	}
+}
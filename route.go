@@ -9,21 +9,27 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/qztseng/gplace/geo"
 )
 
 const (
 	defaultRoutesBaseURL = "https://routes.googleapis.com"
 	routesPath           = "/directions/v2:computeRoutes"
-	routesFieldMask      = "routes.polyline.encodedPolyline"
+	routesFieldMask      = "routes.polyline.encodedPolyline,routes.duration,routes.distanceMeters," +
+		"routes.legs.duration,routes.legs.distanceMeters,routes.legs.startLocation,routes.legs.endLocation"
 )
 
 const (
-	defaultRouteLimit      = 5
-	defaultRouteRadiusM    = 1000
-	defaultRouteWaypoints  = 5
-	maxRouteWaypoints      = 20
-	earthRadiusMeters      = 6371000.0
-	routePolylinePrecision = 1e5
+	defaultRouteLimit     = 5
+	defaultRouteRadiusM   = 1000
+	defaultRouteWaypoints = 5
+	maxRouteWaypoints     = 100
+	maxRouteWorkers       = 20
+	maxRouteIntermediates = 25
+	defaultRouteWorkers   = 4
 )
 
 const (
@@ -34,6 +40,14 @@ const (
 	travelModeTransit    = "TRANSIT"
 )
 
+const (
+	// RouteStrategySample runs a text search around sampled route waypoints.
+	RouteStrategySample = "sample"
+	// RouteStrategyNative sends the route polyline to Text Search
+	// (search along route), which ranks results by detour.
+	RouteStrategyNative = "native"
+)
+
 var travelModes = map[string]struct{}{
 	travelModeDrive:      {},
 	travelModeWalk:       {},
@@ -43,21 +57,99 @@ var travelModes = map[string]struct{}{
 }
 
 // RouteRequest describes a query to search along a route.
+//
+// The origin is one of From (address), FromPlaceID or FromLocation; the
+// destination likewise uses To, ToPlaceID or ToLocation.
 type RouteRequest struct {
-	Query        string  `json:"query"`
-	From         string  `json:"from"`
-	To           string  `json:"to"`
-	Mode         string  `json:"mode,omitempty"`
-	RadiusM      float64 `json:"radius_m,omitempty"`
-	MaxWaypoints int     `json:"max_waypoints,omitempty"`
-	Limit        int     `json:"limit,omitempty"`
-	Language     string  `json:"language,omitempty"`
-	Region       string  `json:"region,omitempty"`
+	Query         string          `json:"query"`
+	From          string          `json:"from,omitempty"`
+	FromPlaceID   string          `json:"from_place_id,omitempty"`
+	FromLocation  *LatLng         `json:"from_location,omitempty"`
+	To            string          `json:"to,omitempty"`
+	ToPlaceID     string          `json:"to_place_id,omitempty"`
+	ToLocation    *LatLng         `json:"to_location,omitempty"`
+	Via           []RouteLocation `json:"via,omitempty"`
+	Mode          string          `json:"mode,omitempty"`
+	Strategy      string          `json:"strategy,omitempty"`
+	Modifiers     *RouteModifiers `json:"modifiers,omitempty"`
+	DepartureTime *time.Time      `json:"departure_time,omitempty"`
+	RadiusM       float64         `json:"radius_m,omitempty"`
+	// MaxWaypoints caps sampled waypoints, one text search each.
+	MaxWaypoints int `json:"max_waypoints,omitempty"`
+	// Sampling picks waypoint placement for the sample strategy:
+	// RouteSamplingEven (default), RouteSamplingDistance or RouteSamplingDense.
+	Sampling string `json:"sampling,omitempty"`
+	// SpacingM is the distance between waypoints for distance and dense
+	// sampling; it defaults to twice RadiusM so search circles touch.
+	SpacingM    float64 `json:"spacing_m,omitempty"`
+	Concurrency int     `json:"concurrency,omitempty"`
+	Limit       int     `json:"limit,omitempty"`
+	Language    string  `json:"language,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	// Detours computes origin→place→destination detours with the Routes API
+	// route matrix for places that do not already have one.
+	Detours bool `json:"detours,omitempty"`
+	// MaxDetourSeconds drops places whose detour is longer or unknown.
+	MaxDetourSeconds int `json:"max_detour_seconds,omitempty"`
+	// SortBy orders places by RouteSortRoute or RouteSortDetour; empty keeps
+	// the strategy's order.
+	SortBy string `json:"sort_by,omitempty"`
+}
+
+// RouteLocation is a route stop given by address, place ID or coordinates.
+// Exactly one field should be set.
+type RouteLocation struct {
+	Address  string  `json:"address,omitempty"`
+	PlaceID  string  `json:"place_id,omitempty"`
+	Location *LatLng `json:"location,omitempty"`
+}
+
+// RouteModifiers lists route features to avoid (DRIVE and TWO_WHEELER only).
+type RouteModifiers struct {
+	AvoidTolls    bool `json:"avoid_tolls,omitempty"`
+	AvoidHighways bool `json:"avoid_highways,omitempty"`
+	AvoidFerries  bool `json:"avoid_ferries,omitempty"`
 }
 
 // RouteResponse contains sampled waypoints with search results.
 type RouteResponse struct {
+	// Route describes the computed route the search ran along.
+	Route     *RouteInfo      `json:"route,omitempty"`
 	Waypoints []RouteWaypoint `json:"waypoints"`
+	// Places lists each place once, in route order.
+	Places []RoutePlace `json:"places,omitempty"`
+}
+
+// RoutePlace is a deduplicated place found along a route. Distances are nil
+// when the place has no location.
+type RoutePlace struct {
+	PlaceSummary
+	// WaypointIndex is the sampled waypoint closest to the place; nil for the
+	// native strategy, which does not sample waypoints.
+	WaypointIndex      *int     `json:"waypoint_index,omitempty"`
+	DistanceAlongM     *float64 `json:"distance_along_m,omitempty"`
+	DistanceFromRouteM *float64 `json:"distance_from_route_m,omitempty"`
+	// DetourSeconds and DetourMeters are the extra travel needed to stop at
+	// the place compared with the direct route.
+	DetourSeconds *int `json:"detour_seconds,omitempty"`
+	DetourMeters  *int `json:"detour_meters,omitempty"`
+}
+
+// RouteInfo summarizes the first route returned by the Routes API.
+type RouteInfo struct {
+	DistanceMeters  int        `json:"distance_meters"`
+	DurationSeconds int        `json:"duration_seconds"`
+	Legs            []RouteLeg `json:"legs,omitempty"`
+	EncodedPolyline string     `json:"encoded_polyline"`
+	Polyline        []LatLng   `json:"polyline"`
+}
+
+// RouteLeg is the part of a route between two consecutive stops.
+type RouteLeg struct {
+	DistanceMeters  int     `json:"distance_meters"`
+	DurationSeconds int     `json:"duration_seconds"`
+	StartLocation   *LatLng `json:"start_location,omitempty"`
+	EndLocation     *LatLng `json:"end_location,omitempty"`
 }
 
 // RouteWaypoint ties a sampled route location to search results.
@@ -66,69 +158,309 @@ type RouteWaypoint struct {
 	Results  []PlaceSummary `json:"results"`
 }
 
+// RouteProgress receives partial route search results as they become
+// available. Callbacks run one at a time; nil callbacks are skipped.
+type RouteProgress struct {
+	// Route is called once the route has been computed, before any search.
+	Route func(route RouteInfo)
+	// Waypoint is called as each waypoint search finishes. Waypoints finish
+	// in any order; index is the position in RouteResponse.Waypoints.
+	Waypoint func(index int, waypoint RouteWaypoint)
+}
+
 // Route searches for places along a route between two locations.
 func (c *Client) Route(ctx context.Context, req RouteRequest) (RouteResponse, error) {
+	return c.RouteWithProgress(ctx, req, RouteProgress{})
+}
+
+// RouteWithProgress is like Route but reports the route and each finished
+// waypoint search to progress before returning the full response.
+func (c *Client) RouteWithProgress(ctx context.Context, req RouteRequest, progress RouteProgress) (RouteResponse, error) {
 	req = applyRouteDefaults(req)
 	if err := validateRouteRequest(req); err != nil {
 		return RouteResponse{}, err
 	}
 
-	polyline, err := c.computeRoutePolyline(ctx, req)
+	route, err := c.computeRoute(ctx, req)
 	if err != nil {
 		return RouteResponse{}, err
 	}
+	if progress.Route != nil {
+		progress.Route(route)
+	}
+	points := route.Polyline
 
-	points, err := decodePolyline(polyline)
+	var response RouteResponse
+	if req.Strategy == RouteStrategyNative {
+		response, err = c.searchAlongRoute(ctx, req, route)
+		if err != nil {
+			return RouteResponse{}, err
+		}
+	} else {
+		waypoints := routeSamplePoints(req, points)
+		if len(waypoints) == 0 {
+			return RouteResponse{}, errors.New("gplace: no route waypoints")
+		}
+
+		results, err := c.searchWaypoints(ctx, req, waypoints, progress.Waypoint)
+		if err != nil {
+			return RouteResponse{}, err
+		}
+		response = RouteResponse{
+			Waypoints: results,
+			Places:    dedupeRoutePlaces(points, results),
+		}
+	}
+
+	if req.Detours {
+		if err := c.addDetours(ctx, req, response.Places); err != nil {
+			return RouteResponse{}, err
+		}
+	}
+	response.Places = orderRoutePlaces(req, response.Places)
+	response.Route = &route
+	return response, nil
+}
+
+// ComputeRoute returns the route between two locations without searching for
+// places. Query and the search settings in req are ignored.
+func (c *Client) ComputeRoute(ctx context.Context, req RouteRequest) (RouteInfo, error) {
+	req = applyRouteDefaults(req)
+	if err := validateRouteEndpoints(req); err != nil {
+		return RouteInfo{}, err
+	}
+	return c.computeRoute(ctx, req)
+}
+
+// searchWaypoints runs one text search per waypoint on a bounded worker pool.
+// The first failure cancels the remaining searches. done, if set, is called
+// under a lock as each search succeeds.
+func (c *Client) searchWaypoints(
+	ctx context.Context,
+	req RouteRequest,
+	waypoints []LatLng,
+	done func(int, RouteWaypoint),
+) ([]RouteWaypoint, error) {
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]RouteWaypoint, len(waypoints))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	var doneMu sync.Mutex
+
+	workers := min(req.Concurrency, len(waypoints))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				waypoint := waypoints[i]
+				response, err := c.Search(searchCtx, SearchRequest{
+					Query:    req.Query,
+					Limit:    req.Limit,
+					Language: req.Language,
+					Region:   req.Region,
+					LocationBias: &LocationBias{
+						Lat:     waypoint.Lat,
+						Lng:     waypoint.Lng,
+						RadiusM: req.RadiusM,
+					},
+				})
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				results[i] = RouteWaypoint{
+					Location: waypoint,
+					Results:  response.Results,
+				}
+				if done != nil && searchCtx.Err() == nil {
+					doneMu.Lock()
+					done(i, results[i])
+					doneMu.Unlock()
+				}
+			}
+		}()
+	}
+
+send:
+	for i := range waypoints {
+		select {
+		case jobs <- i:
+		case <-searchCtx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// searchAlongRoute runs a single Text Search constrained to the route polyline
+// and derives detours from the returned routing summaries when the route has
+// no intermediate stops.
+func (c *Client) searchAlongRoute(ctx context.Context, req RouteRequest, route RouteInfo) (RouteResponse, error) {
+	points := route.Polyline
+	response, err := c.Search(ctx, SearchRequest{
+		Query:    req.Query,
+		Limit:    req.Limit,
+		Language: req.Language,
+		Region:   req.Region,
+		AlongRoute: &SearchAlongRoute{
+			EncodedPolyline: route.EncodedPolyline,
+			Origin:          &points[0],
+			TravelMode:      req.Mode,
+		},
+	})
 	if err != nil {
 		return RouteResponse{}, err
 	}
 
-	waypoints := sampleWaypoints(points, req.MaxWaypoints)
-	if len(waypoints) == 0 {
-		return RouteResponse{}, errors.New("gplace: no route waypoints")
+	line := geo.NewLine(points)
+	places := make([]RoutePlace, 0, len(response.Results))
+	for i, place := range response.Results {
+		routePlace := RoutePlace{PlaceSummary: place}
+		if place.Location != nil {
+			projection := line.Closest(*place.Location)
+			routePlace.DistanceAlongM = &projection.Along
+			routePlace.DistanceFromRouteM = &projection.Offset
+		}
+		// Summaries cover origin→place→destination only; with stops the
+		// matrix costs detours per leg instead.
+		if i < len(response.RoutingSummaries) && len(req.Via) == 0 {
+			routePlace.DetourSeconds, routePlace.DetourMeters = summaryDetour(response.RoutingSummaries[i], route)
+		}
+		places = append(places, routePlace)
 	}
+	return RouteResponse{Waypoints: []RouteWaypoint{}, Places: places}, nil
+}
 
-	results := make([]RouteWaypoint, 0, len(waypoints))
-	for _, waypoint := range waypoints {
-		response, err := c.Search(ctx, SearchRequest{
-			Query:    req.Query,
-			Limit:    req.Limit,
-			Language: req.Language,
-			Region:   req.Region,
-			LocationBias: &LocationBias{
-				Lat:     waypoint.Lat,
-				Lng:     waypoint.Lng,
-				RadiusM: req.RadiusM,
-			},
-		})
-		if err != nil {
-			return RouteResponse{}, err
+// summaryDetour compares origin→place→destination legs with the direct route.
+func summaryDetour(summary RoutingSummary, route RouteInfo) (*int, *int) {
+	if len(summary.Legs) < 2 {
+		return nil, nil
+	}
+	var seconds, meters int
+	for _, leg := range summary.Legs {
+		seconds += leg.DurationSeconds
+		meters += leg.DistanceMeters
+	}
+	seconds = max(seconds-route.DurationSeconds, 0)
+	meters = max(meters-route.DistanceMeters, 0)
+	return &seconds, &meters
+}
+
+// dedupeRoutePlaces merges waypoint results by place ID and annotates each
+// place with its position relative to the route polyline.
+func dedupeRoutePlaces(points []LatLng, waypoints []RouteWaypoint) []RoutePlace {
+	line := geo.NewLine(points)
+	seen := make(map[string]struct{})
+	places := make([]RoutePlace, 0)
+	for index, waypoint := range waypoints {
+		for _, place := range waypoint.Results {
+			if place.PlaceID != "" {
+				if _, ok := seen[place.PlaceID]; ok {
+					continue
+				}
+				seen[place.PlaceID] = struct{}{}
+			}
+
+			waypointIndex := index
+			routePlace := RoutePlace{PlaceSummary: place, WaypointIndex: &waypointIndex}
+			if place.Location != nil {
+				waypointIndex = closestWaypoint(waypoints, *place.Location)
+				projection := line.Closest(*place.Location)
+				routePlace.DistanceAlongM = &projection.Along
+				routePlace.DistanceFromRouteM = &projection.Offset
+			}
+			places = append(places, routePlace)
 		}
-		results = append(results, RouteWaypoint{
-			Location: waypoint,
-			Results:  response.Results,
-		})
 	}
 
-	return RouteResponse{Waypoints: results}, nil
+	// Route order; places without a location keep their waypoint order at the end.
+	sort.SliceStable(places, func(i, j int) bool {
+		return lessOptional(places[i].DistanceAlongM, places[j].DistanceAlongM)
+	})
+	return places
+}
+
+func closestWaypoint(waypoints []RouteWaypoint, point LatLng) int {
+	closest := 0
+	best := math.Inf(1)
+	for i, waypoint := range waypoints {
+		if distance := geo.Distance(waypoint.Location, point); distance < best {
+			best = distance
+			closest = i
+		}
+	}
+	return closest
 }
 
 func applyRouteDefaults(req RouteRequest) RouteRequest {
 	req.Query = strings.TrimSpace(req.Query)
 	req.From = strings.TrimSpace(req.From)
+	req.FromPlaceID = strings.TrimSpace(req.FromPlaceID)
 	req.To = strings.TrimSpace(req.To)
+	req.ToPlaceID = strings.TrimSpace(req.ToPlaceID)
+	if len(req.Via) > 0 {
+		via := make([]RouteLocation, 0, len(req.Via))
+		for _, stop := range req.Via {
+			stop.Address = strings.TrimSpace(stop.Address)
+			stop.PlaceID = strings.TrimSpace(stop.PlaceID)
+			via = append(via, stop)
+		}
+		req.Via = via
+	}
 	req.Mode = strings.ToUpper(strings.TrimSpace(req.Mode))
 	if req.Mode == "" {
 		req.Mode = travelModeDrive
 	}
+	req.Strategy = strings.ToLower(strings.TrimSpace(req.Strategy))
+	if req.Strategy == "" {
+		req.Strategy = RouteStrategySample
+	}
 	if req.Limit == 0 {
 		req.Limit = defaultRouteLimit
 	}
 	if req.RadiusM == 0 {
 		req.RadiusM = defaultRouteRadiusM
 	}
+	req.Sampling = strings.ToLower(strings.TrimSpace(req.Sampling))
+	if req.Sampling == "" {
+		req.Sampling = RouteSamplingEven
+		if req.SpacingM > 0 {
+			req.Sampling = RouteSamplingDistance
+		}
+	}
+	if req.Sampling != RouteSamplingEven && req.SpacingM == 0 {
+		req.SpacingM = 2 * req.RadiusM
+	}
 	if req.MaxWaypoints == 0 {
 		req.MaxWaypoints = defaultRouteWaypoints
+		if req.Sampling != RouteSamplingEven {
+			req.MaxWaypoints = defaultRouteSampleBudget
+		}
+	}
+	if req.Concurrency == 0 {
+		req.Concurrency = defaultRouteWorkers
+	}
+	req.SortBy = strings.ToLower(strings.TrimSpace(req.SortBy))
+	if req.MaxDetourSeconds > 0 || req.SortBy == RouteSortDetour {
+		// Filtering or sorting by detour needs detours for every place.
+		req.Detours = true
 	}
 	return req
 }
@@ -137,11 +469,8 @@ func validateRouteRequest(req RouteRequest) error {
 	if req.Query == "" {
 		return ValidationError{Field: "query", Message: "required"}
 	}
-	if req.From == "" {
-		return ValidationError{Field: "from", Message: "required"}
-	}
-	if req.To == "" {
-		return ValidationError{Field: "to", Message: "required"}
+	if err := validateRouteEndpoints(req); err != nil {
+		return err
 	}
 	if req.Limit < 1 || req.Limit > maxSearchLimit {
 		return ValidationError{Field: "limit", Message: fmt.Sprintf("must be 1-%d", maxSearchLimit)}
@@ -152,24 +481,157 @@ func validateRouteRequest(req RouteRequest) error {
 	if req.MaxWaypoints < 1 || req.MaxWaypoints > maxRouteWaypoints {
 		return ValidationError{Field: "max_waypoints", Message: fmt.Sprintf("must be 1-%d", maxRouteWaypoints)}
 	}
+	if req.Concurrency < 1 || req.Concurrency > maxRouteWorkers {
+		return ValidationError{Field: "concurrency", Message: fmt.Sprintf("must be 1-%d", maxRouteWorkers)}
+	}
+	switch req.Sampling {
+	case RouteSamplingEven:
+		if req.SpacingM != 0 {
+			return ValidationError{Field: "spacing_m", Message: "requires distance or dense sampling"}
+		}
+	case RouteSamplingDistance, RouteSamplingDense:
+		if req.SpacingM <= 0 {
+			return ValidationError{Field: "spacing_m", Message: "must be > 0"}
+		}
+	default:
+		return ValidationError{Field: "sampling", Message: "must be even, distance, or dense"}
+	}
+	if req.Strategy != RouteStrategySample && req.Strategy != RouteStrategyNative {
+		return ValidationError{Field: "strategy", Message: "must be sample or native"}
+	}
+	if req.Strategy == RouteStrategyNative && req.Mode == travelModeTransit {
+		return ValidationError{Field: "strategy", Message: "native is not supported for TRANSIT"}
+	}
+	if req.MaxDetourSeconds < 0 {
+		return ValidationError{Field: "max_detour_seconds", Message: "must be >= 0"}
+	}
+	if req.SortBy != "" && req.SortBy != RouteSortRoute && req.SortBy != RouteSortDetour {
+		return ValidationError{Field: "sort_by", Message: "must be route or detour"}
+	}
+	return nil
+}
+
+// validateRouteEndpoints checks the settings sent to computeRoutes.
+func validateRouteEndpoints(req RouteRequest) error {
+	if err := validateRouteLocation("from", routeOrigin(req)); err != nil {
+		return err
+	}
+	if err := validateRouteLocation("to", routeDestination(req)); err != nil {
+		return err
+	}
+	if len(req.Via) > maxRouteIntermediates {
+		return ValidationError{Field: "via", Message: fmt.Sprintf("must have at most %d stops", maxRouteIntermediates)}
+	}
+	for i, stop := range req.Via {
+		if err := validateRouteLocation(fmt.Sprintf("via[%d]", i), stop); err != nil {
+			return err
+		}
+	}
 	if _, ok := travelModes[req.Mode]; !ok {
 		return ValidationError{Field: "mode", Message: "must be DRIVE, WALK, BICYCLE, TWO_WHEELER, or TRANSIT"}
 	}
+	if req.Mode == travelModeTransit && len(req.Via) > 0 {
+		return ValidationError{Field: "via", Message: "not supported for TRANSIT"}
+	}
+	if req.Modifiers != nil && *req.Modifiers != (RouteModifiers{}) &&
+		req.Mode != travelModeDrive && req.Mode != travelModeTwoWheeler {
+		return ValidationError{Field: "modifiers", Message: "only supported for DRIVE and TWO_WHEELER"}
+	}
 	return nil
 }
 
-func (c *Client) computeRoutePolyline(ctx context.Context, req RouteRequest) (string, error) {
+func validateRouteLocation(field string, loc RouteLocation) error {
+	set := 0
+	if loc.Address != "" {
+		set++
+	}
+	if loc.PlaceID != "" {
+		set++
+	}
+	if loc.Location != nil {
+		set++
+		if loc.Location.Lat < -90 || loc.Location.Lat > 90 {
+			return ValidationError{Field: field + ".lat", Message: "must be -90..90"}
+		}
+		if loc.Location.Lng < -180 || loc.Location.Lng > 180 {
+			return ValidationError{Field: field + ".lng", Message: "must be -180..180"}
+		}
+	}
+	if set == 0 {
+		return ValidationError{Field: field, Message: "required"}
+	}
+	if set > 1 {
+		return ValidationError{Field: field, Message: "set only one of address, place ID or location"}
+	}
+	return nil
+}
+
+func routeOrigin(req RouteRequest) RouteLocation {
+	return RouteLocation{Address: req.From, PlaceID: req.FromPlaceID, Location: req.FromLocation}
+}
+
+func routeDestination(req RouteRequest) RouteLocation {
+	return RouteLocation{Address: req.To, PlaceID: req.ToPlaceID, Location: req.ToLocation}
+}
+
+// routeWaypointPayload maps a stop to the Routes API Waypoint message.
+func routeWaypointPayload(loc RouteLocation) map[string]any {
+	switch {
+	case loc.PlaceID != "":
+		return map[string]any{"placeId": loc.PlaceID}
+	case loc.Location != nil:
+		return map[string]any{
+			"location": map[string]any{
+				"latLng": map[string]any{
+					"latitude":  loc.Location.Lat,
+					"longitude": loc.Location.Lng,
+				},
+			},
+		}
+	default:
+		return map[string]any{"address": loc.Address}
+	}
+}
+
+func routeModifiersPayload(modifiers *RouteModifiers) map[string]any {
+	return map[string]any{
+		"avoidTolls":    modifiers.AvoidTolls,
+		"avoidHighways": modifiers.AvoidHighways,
+		"avoidFerries":  modifiers.AvoidFerries,
+	}
+}
+
+// addRouteDeparture sets the departure time shared by routes and matrix requests.
+func addRouteDeparture(body map[string]any, req RouteRequest) {
+	if req.DepartureTime == nil {
+		return
+	}
+	body["departureTime"] = req.DepartureTime.UTC().Format(time.RFC3339)
+	if req.Mode == travelModeDrive || req.Mode == travelModeTwoWheeler {
+		// Departure time only affects driving routes when traffic is considered.
+		body["routingPreference"] = "TRAFFIC_AWARE"
+	}
+}
+
+func (c *Client) computeRoute(ctx context.Context, req RouteRequest) (RouteInfo, error) {
 	body := map[string]any{
-		"origin": map[string]any{
-			"address": req.From,
-		},
-		"destination": map[string]any{
-			"address": req.To,
-		},
+		"origin":           routeWaypointPayload(routeOrigin(req)),
+		"destination":      routeWaypointPayload(routeDestination(req)),
 		"travelMode":       req.Mode,
 		"polylineQuality":  "OVERVIEW",
 		"polylineEncoding": "ENCODED_POLYLINE",
 	}
+	if len(req.Via) > 0 {
+		intermediates := make([]map[string]any, 0, len(req.Via))
+		for _, stop := range req.Via {
+			intermediates = append(intermediates, routeWaypointPayload(stop))
+		}
+		body["intermediates"] = intermediates
+	}
+	if req.Modifiers != nil && *req.Modifiers != (RouteModifiers{}) {
+		body["routeModifiers"] = routeModifiersPayload(req.Modifiers)
+	}
+	addRouteDeparture(body, req)
 	if req.Language != "" {
 		body["languageCode"] = req.Language
 	}
@@ -180,68 +642,41 @@ func (c *Client) computeRoutePolyline(ctx context.Context, req RouteRequest) (st
 	endpoint := c.routesBaseURL + routesPath
 	payload, err := c.doRequest(ctx, http.MethodPost, endpoint, body, routesFieldMask)
 	if err != nil {
-		return "", err
+		return RouteInfo{}, err
 	}
 
 	var response routesResponse
 	if err := json.Unmarshal(payload, &response); err != nil {
-		return "", fmt.Errorf("gplace: decode route response: %w", err)
+		return RouteInfo{}, fmt.Errorf("gplace: decode route response: %w", err)
 	}
 	if len(response.Routes) == 0 {
-		return "", errors.New("gplace: no routes returned")
+		return RouteInfo{}, errors.New("gplace: no routes returned")
 	}
-	polyline := strings.TrimSpace(response.Routes[0].Polyline.EncodedPolyline)
+	route := response.Routes[0]
+	polyline := strings.TrimSpace(route.Polyline.EncodedPolyline)
 	if polyline == "" {
-		return "", errors.New("gplace: empty route polyline")
+		return RouteInfo{}, errors.New("gplace: empty route polyline")
 	}
-	return polyline, nil
-}
-
-func decodePolyline(encoded string) ([]LatLng, error) {
-	if strings.TrimSpace(encoded) == "" {
-		return nil, errors.New("gplace: empty polyline")
+	points, err := geo.Decode(polyline, geo.Precision5)
+	if err != nil {
+		return RouteInfo{}, fmt.Errorf("gplace: decode route polyline: %w", err)
 	}
-	points := make([]LatLng, 0, len(encoded)/4)
-	var lat, lng int
-	for i := 0; i < len(encoded); {
-		var delta int
-		var shift uint
-		for {
-			if i >= len(encoded) {
-				return nil, errors.New("gplace: invalid polyline")
-			}
-			b := int(encoded[i]) - 63
-			i++
-			delta |= (b & 0x1f) << shift
-			shift += 5
-			if b < 0x20 {
-				break
-			}
-		}
-		lat += (delta >> 1) ^ (-(delta & 1))
 
-		delta = 0
-		shift = 0
-		for {
-			if i >= len(encoded) {
-				return nil, errors.New("gplace: invalid polyline")
-			}
-			b := int(encoded[i]) - 63
-			i++
-			delta |= (b & 0x1f) << shift
-			shift += 5
-			if b < 0x20 {
-				break
-			}
-		}
-		lng += (delta >> 1) ^ (-(delta & 1))
-
-		points = append(points, LatLng{
-			Lat: float64(lat) / routePolylinePrecision,
-			Lng: float64(lng) / routePolylinePrecision,
+	info := RouteInfo{
+		DistanceMeters:  route.DistanceMeters,
+		DurationSeconds: durationSeconds(route.Duration),
+		EncodedPolyline: polyline,
+		Polyline:        points,
+	}
+	for _, leg := range route.Legs {
+		info.Legs = append(info.Legs, RouteLeg{
+			DistanceMeters:  leg.DistanceMeters,
+			DurationSeconds: durationSeconds(leg.Duration),
+			StartLocation:   leg.StartLocation.latLng(),
+			EndLocation:     leg.EndLocation.latLng(),
 		})
 	}
-	return points, nil
+	return info, nil
 }
 
 func sampleWaypoints(points []LatLng, maxWaypoints int) []LatLng {
@@ -251,15 +686,15 @@ func sampleWaypoints(points []LatLng, maxWaypoints int) []LatLng {
 	if len(points) == 1 {
 		return []LatLng{points[0]}
 	}
+	line := geo.NewLine(points)
 	if maxWaypoints == 1 {
-		return []LatLng{pointAtDistance(points, totalDistance(points)/2)}
+		return []LatLng{line.PointAt(line.Length() / 2)}
 	}
 	if maxWaypoints >= len(points) {
 		return uniqueWaypoints(points)
 	}
 
-	cumulative := cumulativeDistances(points)
-	total := cumulative[len(cumulative)-1]
+	total := line.Length()
 	if total == 0 {
 		return []LatLng{points[0]}
 	}
@@ -267,8 +702,7 @@ func sampleWaypoints(points []LatLng, maxWaypoints int) []LatLng {
 
 	sampled := make([]LatLng, 0, maxWaypoints)
 	for i := 0; i < maxWaypoints; i++ {
-		target := spacing * float64(i)
-		point := pointAtCumulative(points, cumulative, target)
+		point := line.PointAt(spacing * float64(i))
 		if len(sampled) == 0 || !samePoint(sampled[len(sampled)-1], point) {
 			sampled = append(sampled, point)
 		}
@@ -276,60 +710,6 @@ func sampleWaypoints(points []LatLng, maxWaypoints int) []LatLng {
 	return sampled
 }
 
-func cumulativeDistances(points []LatLng) []float64 {
-	distances := make([]float64, len(points))
-	for i := 1; i < len(points); i++ {
-		distances[i] = distances[i-1] + distanceMeters(points[i-1], points[i])
-	}
-	return distances
-}
-
-func totalDistance(points []LatLng) float64 {
-	if len(points) < 2 {
-		return 0
-	}
-	var total float64
-	for i := 1; i < len(points); i++ {
-		total += distanceMeters(points[i-1], points[i])
-	}
-	return total
-}
-
-func pointAtDistance(points []LatLng, target float64) LatLng {
-	if len(points) == 0 {
-		return LatLng{}
-	}
-	cumulative := cumulativeDistances(points)
-	return pointAtCumulative(points, cumulative, target)
-}
-
-func pointAtCumulative(points []LatLng, cumulative []float64, target float64) LatLng {
-	if target <= 0 {
-		return points[0]
-	}
-	total := cumulative[len(cumulative)-1]
-	if target >= total {
-		return points[len(points)-1]
-	}
-	index := sort.Search(len(cumulative), func(i int) bool {
-		return cumulative[i] >= target
-	})
-	if index == 0 {
-		return points[0]
-	}
-	prev := points[index-1]
-	next := points[index]
-	segment := cumulative[index] - cumulative[index-1]
-	if segment <= 0 {
-		return next
-	}
-	fraction := (target - cumulative[index-1]) / segment
-	return LatLng{
-		Lat: prev.Lat + (next.Lat-prev.Lat)*fraction,
-		Lng: prev.Lng + (next.Lng-prev.Lng)*fraction,
-	}
-}
-
 func uniqueWaypoints(points []LatLng) []LatLng {
 	result := make([]LatLng, 0, len(points))
 	for _, point := range points {
@@ -345,24 +725,33 @@ func samePoint(a, b LatLng) bool {
 	return math.Abs(a.Lat-b.Lat) < epsilon && math.Abs(a.Lng-b.Lng) < epsilon
 }
 
-func distanceMeters(a, b LatLng) float64 {
-	lat1 := a.Lat * math.Pi / 180
-	lat2 := b.Lat * math.Pi / 180
-	dlat := (b.Lat - a.Lat) * math.Pi / 180
-	dlng := (b.Lng - a.Lng) * math.Pi / 180
-
-	sinDLat := math.Sin(dlat / 2)
-	sinDLng := math.Sin(dlng / 2)
-	value := sinDLat*sinDLat + math.Cos(lat1)*math.Cos(lat2)*sinDLng*sinDLng
-	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(value))
-}
-
 type routesResponse struct {
 	Routes []routeItem `json:"routes"`
 }
 
 type routeItem struct {
-	Polyline routePolyline `json:"polyline"`
+	Polyline       routePolyline  `json:"polyline"`
+	Duration       string         `json:"duration,omitempty"`
+	DistanceMeters int            `json:"distanceMeters,omitempty"`
+	Legs           []routeLegItem `json:"legs,omitempty"`
+}
+
+type routeLegItem struct {
+	Duration       string         `json:"duration,omitempty"`
+	DistanceMeters int            `json:"distanceMeters,omitempty"`
+	StartLocation  *routeLocation `json:"startLocation,omitempty"`
+	EndLocation    *routeLocation `json:"endLocation,omitempty"`
+}
+
+type routeLocation struct {
+	LatLng location `json:"latLng"`
+}
+
+func (l *routeLocation) latLng() *LatLng {
+	if l == nil {
+		return nil
+	}
+	return &LatLng{Lat: l.LatLng.Latitude, Lng: l.LatLng.Longitude}
 }
 
 type routePolyline struct {
@@ -0,0 +1,277 @@
+package gplace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+const (
+	routeMatrixPath      = "/distanceMatrix/v2:computeRouteMatrix"
+	routeMatrixFieldMask = "originIndex,destinationIndex,duration,distanceMeters,status,condition"
+	routeConditionExists = "ROUTE_EXISTS"
+)
+
+const (
+	// RouteSortRoute orders places by distance along the route.
+	RouteSortRoute = "route"
+	// RouteSortDetour orders places by detour time, unknown detours last.
+	RouteSortDetour = "detour"
+)
+
+type matrixCost struct {
+	seconds int
+	meters  int
+}
+
+type matrixKey struct {
+	origin      int
+	destination int
+}
+
+// Route matrix request limits: origins × destinations elements (fewer for
+// TRANSIT), and waypoints given as an address or place ID rather than lat/lng.
+const (
+	maxMatrixElements        = 625
+	maxTransitMatrixElements = 100
+	maxMatrixNamedWaypoints  = 50
+)
+
+// addDetours fills DetourSeconds/DetourMeters for places that lack them. The
+// route is split into legs at its intermediate stops, and each place is
+// inserted into the leg where it costs least: start→place→end compared with
+// start→end, all taken from the route matrix so the legs are costed
+// consistently.
+func (c *Client) addDetours(ctx context.Context, req RouteRequest, places []RoutePlace) error {
+	targets := make([]int, 0, len(places))
+	stops := make([]RouteLocation, 0, len(places))
+	for i, place := range places {
+		if place.DetourSeconds != nil {
+			continue
+		}
+		// Coordinates do not count toward the matrix waypoint limit.
+		switch {
+		case place.Location != nil:
+			stops = append(stops, RouteLocation{Location: place.Location})
+		case place.PlaceID != "":
+			stops = append(stops, RouteLocation{PlaceID: place.PlaceID})
+		default:
+			continue
+		}
+		targets = append(targets, i)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	path := append(append([]RouteLocation{routeOrigin(req)}, req.Via...), routeDestination(req))
+	starts, ends := path[:len(path)-1], path[1:]
+	toStops, err := c.batchRouteMatrix(ctx, req, starts, append(append([]RouteLocation(nil), stops...), ends...))
+	if err != nil {
+		return err
+	}
+	fromStops, err := c.batchRouteMatrix(ctx, req, stops, ends)
+	if err != nil {
+		return err
+	}
+
+	for i, index := range targets {
+		var best *matrixCost
+		for leg := range starts {
+			direct, okDirect := toStops[matrixKey{origin: leg, destination: len(stops) + leg}]
+			toStop, okTo := toStops[matrixKey{origin: leg, destination: i}]
+			fromStop, okFrom := fromStops[matrixKey{origin: i, destination: leg}]
+			if !okDirect || !okTo || !okFrom {
+				continue
+			}
+			detour := matrixCost{
+				seconds: max(toStop.seconds+fromStop.seconds-direct.seconds, 0),
+				meters:  max(toStop.meters+fromStop.meters-direct.meters, 0),
+			}
+			if best == nil || detour.seconds < best.seconds {
+				best = &detour
+			}
+		}
+		if best == nil {
+			continue
+		}
+		places[index].DetourSeconds = &best.seconds
+		places[index].DetourMeters = &best.meters
+	}
+	return nil
+}
+
+// batchRouteMatrix costs every origin → destination pair, splitting them
+// into matrix requests that stay within the element and waypoint limits.
+func (c *Client) batchRouteMatrix(
+	ctx context.Context,
+	req RouteRequest,
+	origins []RouteLocation,
+	destinations []RouteLocation,
+) (map[matrixKey]matrixCost, error) {
+	maxElements := maxMatrixElements
+	if req.Mode == travelModeTransit {
+		maxElements = maxTransitMatrixElements
+	}
+	costs := map[matrixKey]matrixCost{}
+	// Leave room for at least one named destination in each request.
+	for _, originBatch := range matrixBatches(origins, maxElements, maxMatrixNamedWaypoints-1) {
+		batchOrigins := origins[originBatch.start:originBatch.end]
+		named := namedWaypoints(batchOrigins)
+		for _, destinationBatch := range matrixBatches(destinations, maxElements/len(batchOrigins), maxMatrixNamedWaypoints-named) {
+			batch, err := c.computeRouteMatrix(ctx, req, batchOrigins, destinations[destinationBatch.start:destinationBatch.end])
+			if err != nil {
+				return nil, err
+			}
+			for key, cost := range batch {
+				costs[matrixKey{origin: originBatch.start + key.origin, destination: destinationBatch.start + key.destination}] = cost
+			}
+		}
+	}
+	return costs, nil
+}
+
+type matrixBatch struct {
+	start int
+	end   int
+}
+
+// matrixBatches splits locations into consecutive runs of at most maxSize
+// locations, of which at most maxNamed are not coordinates.
+func matrixBatches(locations []RouteLocation, maxSize int, maxNamed int) []matrixBatch {
+	var batches []matrixBatch
+	start, named := 0, 0
+	for i, location := range locations {
+		isNamed := location.Location == nil
+		if i > start && (i-start == maxSize || (isNamed && named == maxNamed)) {
+			batches = append(batches, matrixBatch{start: start, end: i})
+			start, named = i, 0
+		}
+		if isNamed {
+			named++
+		}
+	}
+	if start < len(locations) {
+		batches = append(batches, matrixBatch{start: start, end: len(locations)})
+	}
+	return batches
+}
+
+func namedWaypoints(locations []RouteLocation) int {
+	named := 0
+	for _, location := range locations {
+		if location.Location == nil {
+			named++
+		}
+	}
+	return named
+}
+
+func (c *Client) computeRouteMatrix(
+	ctx context.Context,
+	req RouteRequest,
+	origins []RouteLocation,
+	destinations []RouteLocation,
+) (map[matrixKey]matrixCost, error) {
+	originPayload := make([]map[string]any, 0, len(origins))
+	for _, origin := range origins {
+		entry := map[string]any{"waypoint": routeWaypointPayload(origin)}
+		if req.Modifiers != nil && *req.Modifiers != (RouteModifiers{}) {
+			entry["routeModifiers"] = routeModifiersPayload(req.Modifiers)
+		}
+		originPayload = append(originPayload, entry)
+	}
+	destinationPayload := make([]map[string]any, 0, len(destinations))
+	for _, destination := range destinations {
+		destinationPayload = append(destinationPayload, map[string]any{"waypoint": routeWaypointPayload(destination)})
+	}
+
+	body := map[string]any{
+		"origins":      originPayload,
+		"destinations": destinationPayload,
+		"travelMode":   req.Mode,
+	}
+	addRouteDeparture(body, req)
+	if req.Language != "" {
+		body["languageCode"] = req.Language
+	}
+	if req.Region != "" {
+		body["regionCode"] = req.Region
+	}
+
+	endpoint := c.routesBaseURL + routeMatrixPath
+	payload, err := c.doRequest(ctx, http.MethodPost, endpoint, body, routeMatrixFieldMask)
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []routeMatrixElement
+	if err := json.Unmarshal(payload, &elements); err != nil {
+		return nil, fmt.Errorf("gplace: decode route matrix response: %w", err)
+	}
+
+	costs := make(map[matrixKey]matrixCost, len(elements))
+	for _, element := range elements {
+		// Unroutable pairs come back with an error status or another condition.
+		if element.Status != nil && element.Status.Code != 0 {
+			continue
+		}
+		if element.Condition != routeConditionExists {
+			continue
+		}
+		costs[matrixKey{origin: element.OriginIndex, destination: element.DestinationIndex}] = matrixCost{
+			seconds: durationSeconds(element.Duration),
+			meters:  element.DistanceMeters,
+		}
+	}
+	return costs, nil
+}
+
+// orderRoutePlaces applies the max-detour filter and sort order.
+func orderRoutePlaces(req RouteRequest, places []RoutePlace) []RoutePlace {
+	if req.MaxDetourSeconds > 0 {
+		kept := places[:0]
+		for _, place := range places {
+			if place.DetourSeconds != nil && *place.DetourSeconds <= req.MaxDetourSeconds {
+				kept = append(kept, place)
+			}
+		}
+		places = kept
+	}
+
+	switch req.SortBy {
+	case RouteSortRoute:
+		sort.SliceStable(places, func(i, j int) bool {
+			return lessOptional(places[i].DistanceAlongM, places[j].DistanceAlongM)
+		})
+	case RouteSortDetour:
+		sort.SliceStable(places, func(i, j int) bool {
+			return lessOptional(places[i].DetourSeconds, places[j].DetourSeconds)
+		})
+	}
+	return places
+}
+
+// lessOptional orders known values ascending, with nil values last.
+func lessOptional[T int | float64](a, b *T) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	return *a < *b
+}
+
+type routeMatrixElement struct {
+	OriginIndex      int                `json:"originIndex"`
+	DestinationIndex int                `json:"destinationIndex"`
+	Duration         string             `json:"duration,omitempty"`
+	DistanceMeters   int                `json:"distanceMeters,omitempty"`
+	Status           *routeMatrixStatus `json:"status,omitempty"`
+	Condition        string             `json:"condition,omitempty"`
+}
+
+type routeMatrixStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,115 @@
+package gplace
+
+import (
+	"math"
+
+	"github.com/qztseng/gplace/geo"
+)
+
+const (
+	// RouteSamplingEven spreads MaxWaypoints evenly along the route.
+	RouteSamplingEven = "even"
+	// RouteSamplingDistance places a waypoint every SpacingM meters, up to the
+	// MaxWaypoints call budget.
+	RouteSamplingDistance = "distance"
+	// RouteSamplingDense is like RouteSamplingDistance, but when the budget
+	// cannot cover the whole route it favors dense (urban) segments.
+	RouteSamplingDense = "dense"
+)
+
+const (
+	defaultRouteSampleBudget = 25
+	maxRouteDenseWeight      = 4.0
+)
+
+// routeSamplePoints picks waypoints for the sample strategy.
+func routeSamplePoints(req RouteRequest, points []LatLng) []LatLng {
+	switch req.Sampling {
+	case RouteSamplingDistance:
+		return sampleWaypointsBySpacing(points, req.SpacingM, req.MaxWaypoints, false)
+	case RouteSamplingDense:
+		return sampleWaypointsBySpacing(points, req.SpacingM, req.MaxWaypoints, true)
+	default:
+		return sampleWaypoints(points, req.MaxWaypoints)
+	}
+}
+
+// sampleWaypointsBySpacing returns ceil(total/spacing) waypoints centered in
+// equal route slices, capped at budget. When dense is set and the budget is
+// binding, slices are measured in weighted distance so segments with many
+// polyline vertices per meter (city streets) receive more waypoints.
+func sampleWaypointsBySpacing(points []LatLng, spacing float64, budget int, dense bool) []LatLng {
+	if len(points) == 0 || spacing <= 0 || budget <= 0 {
+		return nil
+	}
+	line := geo.NewLine(points)
+	cumulative := line.Cumulative
+	total := line.Length()
+	if total == 0 {
+		return []LatLng{points[0]}
+	}
+
+	count := max(int(math.Ceil(total/spacing)), 1)
+	weighted := cumulative
+	if count > budget {
+		count = budget
+		if dense {
+			weighted = weightedDistances(cumulative)
+		}
+	}
+
+	weightedTotal := weighted[len(weighted)-1]
+	sampled := make([]LatLng, 0, count)
+	for i := 0; i < count; i++ {
+		target := weightedTotal * (float64(i) + 0.5) / float64(count)
+		point := line.PointAt(unweightDistance(cumulative, weighted, target))
+		if len(sampled) == 0 || !samePoint(sampled[len(sampled)-1], point) {
+			sampled = append(sampled, point)
+		}
+	}
+	return sampled
+}
+
+// weightedDistances scales each segment by how much shorter it is than the
+// mean segment, between 1 and maxRouteDenseWeight. Overview polylines use
+// many short segments on winding urban streets and few long ones on highways.
+func weightedDistances(cumulative []float64) []float64 {
+	segments := 0
+	for i := 1; i < len(cumulative); i++ {
+		if cumulative[i] > cumulative[i-1] {
+			segments++
+		}
+	}
+	weighted := make([]float64, len(cumulative))
+	if segments == 0 {
+		copy(weighted, cumulative)
+		return weighted
+	}
+	mean := cumulative[len(cumulative)-1] / float64(segments)
+
+	for i := 1; i < len(cumulative); i++ {
+		length := cumulative[i] - cumulative[i-1]
+		weight := 1.0
+		if length > 0 {
+			weight = math.Min(math.Max(mean/length, 1), maxRouteDenseWeight)
+		}
+		weighted[i] = weighted[i-1] + length*weight
+	}
+	return weighted
+}
+
+// unweightDistance maps a weighted distance back to meters along the route.
+func unweightDistance(cumulative []float64, weighted []float64, target float64) float64 {
+	for i := 1; i < len(weighted); i++ {
+		if target > weighted[i] {
+			continue
+		}
+		span := weighted[i] - weighted[i-1]
+		if span == 0 {
+			return cumulative[i]
+		}
+		ratio := (target - weighted[i-1]) / span
+		return cumulative[i-1] + ratio*(cumulative[i]-cumulative[i-1])
+	}
+	return cumulative[len(cumulative)-1]
+}
@@ -3,12 +3,21 @@ package gplace
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/qztseng/gplace/geo"
 )
 
-func TestComputeRoutePolyline(t *testing.T) {
+func TestComputeRoute(t *testing.T) {
 	var gotBody map[string]any
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != routesPath {
@@ -25,46 +34,119 @@ func TestComputeRoutePolyline(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
-	polyline, err := client.computeRoutePolyline(context.Background(), RouteRequest{
+	route, err := client.computeRoute(context.Background(), RouteRequest{
 		From: "Seattle",
 		To:   "Portland",
 		Mode: travelModeDrive,
 	})
 	if err != nil {
-		t.Fatalf("computeRoutePolyline error: %v", err)
+		t.Fatalf("computeRoute error: %v", err)
 	}
-	if polyline == "" {
+	if route.EncodedPolyline == "" {
 		t.Fatalf("expected polyline")
 	}
+	if len(route.Polyline) != 3 {
+		t.Fatalf("expected decoded polyline, got %#v", route.Polyline)
+	}
 	if gotBody["travelMode"] != travelModeDrive {
 		t.Fatalf("unexpected travelMode: %#v", gotBody["travelMode"])
 	}
 }
 
-func TestDecodePolyline(t *testing.T) {
-	points, err := decodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+func TestComputeRouteMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"routes": [{
+  "polyline": {"encodedPolyline": "_p~iF~ps|U_ulLnnqC_mqNvxq` + "`" + `@"},
+  "duration": "5400s",
+  "distanceMeters": 150000,
+  "legs": [
+    {"duration": "1800s", "distanceMeters": 50000,
+     "startLocation": {"latLng": {"latitude": 38.5, "longitude": -120.2}},
+     "endLocation": {"latLng": {"latitude": 40.7, "longitude": -120.95}}},
+    {"duration": "3600s", "distanceMeters": 100000}
+  ]
+}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
+	route, err := client.ComputeRoute(context.Background(), RouteRequest{
+		From: "A",
+		To:   "B",
+		Via:  []RouteLocation{{Address: "C"}},
+	})
 	if err != nil {
-		t.Fatalf("decodePolyline error: %v", err)
+		t.Fatalf("compute route error: %v", err)
 	}
-	if len(points) != 3 {
-		t.Fatalf("expected 3 points, got %d", len(points))
+	if route.DistanceMeters != 150000 || route.DurationSeconds != 5400 {
+		t.Fatalf("unexpected totals: %#v", route)
 	}
-	if points[0].Lat != 38.5 || points[0].Lng != -120.2 {
-		t.Fatalf("unexpected first point: %#v", points[0])
+	if len(route.Legs) != 2 || route.Legs[0].DurationSeconds != 1800 || route.Legs[1].DistanceMeters != 100000 {
+		t.Fatalf("unexpected legs: %#v", route.Legs)
+	}
+	if route.Legs[0].EndLocation == nil || route.Legs[0].EndLocation.Lat != 40.7 || route.Legs[1].StartLocation != nil {
+		t.Fatalf("unexpected leg locations: %#v", route.Legs)
 	}
-}
 
-func TestDecodePolylineInvalid(t *testing.T) {
-	_, err := decodePolyline("")
-	if err == nil {
-		t.Fatalf("expected decode error")
+	_, err = client.ComputeRoute(context.Background(), RouteRequest{To: "B"})
+	validation, ok := err.(ValidationError)
+	if !ok || validation.Field != "from" {
+		t.Fatalf("expected from validation error, got %v", err)
 	}
 }
 
-func TestDecodePolylineMalformed(t *testing.T) {
-	_, err := decodePolyline("abc")
-	if err == nil {
-		t.Fatalf("expected malformed error")
+func TestComputeRouteStopsAndModifiers(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		_, _ = w.Write([]byte("{\"routes\": [{\"polyline\": {\"encodedPolyline\": \"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+	}))
+	defer server.Close()
+
+	departure := time.Date(2026, 5, 1, 9, 30, 0, 0, time.FixedZone("PDT", -7*3600))
+	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
+	_, err := client.computeRoute(context.Background(), RouteRequest{
+		FromPlaceID: "place-origin",
+		ToLocation:  &LatLng{Lat: 45.5, Lng: -122.6},
+		Via: []RouteLocation{
+			{Address: "Olympia, WA"},
+			{PlaceID: "place-stop"},
+		},
+		Mode:          travelModeDrive,
+		Modifiers:     &RouteModifiers{AvoidTolls: true},
+		DepartureTime: &departure,
+	})
+	if err != nil {
+		t.Fatalf("computeRoute error: %v", err)
+	}
+
+	origin := gotBody["origin"].(map[string]any)
+	if origin["placeId"] != "place-origin" {
+		t.Fatalf("unexpected origin: %#v", origin)
+	}
+	destination := gotBody["destination"].(map[string]any)
+	latLng := destination["location"].(map[string]any)["latLng"].(map[string]any)
+	if latLng["latitude"] != 45.5 || latLng["longitude"] != -122.6 {
+		t.Fatalf("unexpected destination: %#v", destination)
+	}
+	intermediates := gotBody["intermediates"].([]any)
+	if len(intermediates) != 2 {
+		t.Fatalf("unexpected intermediates: %#v", intermediates)
+	}
+	if intermediates[0].(map[string]any)["address"] != "Olympia, WA" || intermediates[1].(map[string]any)["placeId"] != "place-stop" {
+		t.Fatalf("unexpected intermediates: %#v", intermediates)
+	}
+	modifiers := gotBody["routeModifiers"].(map[string]any)
+	if modifiers["avoidTolls"] != true || modifiers["avoidHighways"] != false {
+		t.Fatalf("unexpected route modifiers: %#v", modifiers)
+	}
+	if gotBody["departureTime"] != "2026-05-01T16:30:00Z" {
+		t.Fatalf("unexpected departureTime: %#v", gotBody["departureTime"])
+	}
+	if gotBody["routingPreference"] != "TRAFFIC_AWARE" {
+		t.Fatalf("unexpected routingPreference: %#v", gotBody["routingPreference"])
 	}
 }
 
@@ -114,42 +196,78 @@ func TestSampleWaypointsMaxExceedsPoints(t *testing.T) {
 	}
 }
 
-func TestPointAtDistanceBounds(t *testing.T) {
-	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}}
-	cumulative := cumulativeDistances(points)
-	if got := pointAtCumulative(points, cumulative, -1); got != points[0] {
-		t.Fatalf("expected first point, got %#v", got)
+func TestSampleWaypointsBySpacing(t *testing.T) {
+	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}
+	total := geo.Length(points)
+
+	sampled := sampleWaypointsBySpacing(points, 10000, 100, false)
+	if len(sampled) != int(math.Ceil(total/10000)) {
+		t.Fatalf("expected one waypoint per 10 km, got %d", len(sampled))
 	}
-	if got := pointAtCumulative(points, cumulative, cumulative[len(cumulative)-1]+1); got != points[1] {
-		t.Fatalf("expected last point, got %#v", got)
+	gap := geo.Distance(sampled[0], sampled[1])
+	if gap > 10000 || math.Abs(geo.Distance(points[0], sampled[0])-gap/2) > 1 {
+		t.Fatalf("unexpected spacing: first=%f gap=%f", geo.Distance(points[0], sampled[0]), gap)
 	}
-}
 
-func TestUniqueWaypoints(t *testing.T) {
-	points := []LatLng{{Lat: 1, Lng: 1}, {Lat: 1, Lng: 1}, {Lat: 2, Lng: 2}}
-	unique := uniqueWaypoints(points)
-	if len(unique) != 2 {
-		t.Fatalf("expected 2 unique points, got %d", len(unique))
+	budgeted := sampleWaypointsBySpacing(points, 10000, 4, false)
+	if len(budgeted) != 4 {
+		t.Fatalf("expected budget of 4 waypoints, got %d", len(budgeted))
 	}
-}
 
-func TestDistanceMeters(t *testing.T) {
-	distance := distanceMeters(LatLng{Lat: 0, Lng: 0}, LatLng{Lat: 0, Lng: 1})
-	if distance <= 0 {
-		t.Fatalf("expected positive distance")
+	if got := sampleWaypointsBySpacing(points, 1e9, 4, false); len(got) != 1 {
+		t.Fatalf("expected a single waypoint for a short route, got %d", len(got))
+	}
+	if got := sampleWaypointsBySpacing(nil, 1000, 4, false); got != nil {
+		t.Fatalf("expected nil for empty points")
 	}
 }
 
-func TestTotalDistanceEmpty(t *testing.T) {
-	if totalDistance([]LatLng{{Lat: 1, Lng: 1}}) != 0 {
-		t.Fatalf("expected zero distance")
+func TestSampleWaypointsBySpacingDense(t *testing.T) {
+	// Ten short segments over the first 0.1 degrees, then one long segment.
+	points := make([]LatLng, 0, 12)
+	for i := 0; i <= 10; i++ {
+		points = append(points, LatLng{Lat: 0, Lng: float64(i) * 0.01})
+	}
+	points = append(points, LatLng{Lat: 0, Lng: 1})
+
+	inCity := func(sampled []LatLng) int {
+		count := 0
+		for _, point := range sampled {
+			if point.Lng <= 0.1 {
+				count++
+			}
+		}
+		return count
+	}
+	even := sampleWaypointsBySpacing(points, 1000, 5, false)
+	dense := sampleWaypointsBySpacing(points, 1000, 5, true)
+	if len(dense) != 5 || inCity(dense) <= inCity(even) {
+		t.Fatalf("expected dense sampling to favor short segments: even=%v dense=%v", even, dense)
+	}
+
+	// Without a binding budget, dense sampling matches distance sampling.
+	unbounded := sampleWaypointsBySpacing(points, 20000, 100, true)
+	plain := sampleWaypointsBySpacing(points, 20000, 100, false)
+	if len(unbounded) != len(plain) || unbounded[0] != plain[0] {
+		t.Fatalf("expected identical samples: %v %v", unbounded, plain)
 	}
 }
 
-func TestPointAtDistanceEmpty(t *testing.T) {
-	point := pointAtDistance(nil, 10)
-	if point != (LatLng{}) {
-		t.Fatalf("expected empty point")
+func TestUniqueWaypoints(t *testing.T) {
+	points := []LatLng{{Lat: 1, Lng: 1}, {Lat: 1, Lng: 1}, {Lat: 2, Lng: 2}}
+	unique := uniqueWaypoints(points)
+	if len(unique) != 2 || unique[0] != points[0] || unique[1] != points[2] {
+		t.Fatalf("expected 2 unique points, got %v", unique)
+	}
+
+	// Only consecutive repeats within 1e-6 degrees collapse; a return to an
+	// earlier point is kept.
+	points = []LatLng{{Lat: 1, Lng: 1}, {Lat: 1 + 1e-7, Lng: 1}, {Lat: 2, Lng: 2}, {Lat: 1, Lng: 1}}
+	if unique := uniqueWaypoints(points); len(unique) != 3 || unique[2] != points[3] {
+		t.Fatalf("unexpected unique points: %v", unique)
+	}
+	if unique := uniqueWaypoints(nil); len(unique) != 0 {
+		t.Fatalf("expected no points, got %v", unique)
 	}
 }
 
@@ -172,6 +290,88 @@ func TestValidateRouteRequest(t *testing.T) {
 	}
 }
 
+func TestValidateRouteRequestLocations(t *testing.T) {
+	base := RouteRequest{
+		Query:        "coffee",
+		To:           "B",
+		Mode:         travelModeDrive,
+		Limit:        1,
+		RadiusM:      1,
+		MaxWaypoints: 1,
+		Concurrency:  1,
+		Strategy:     RouteStrategySample,
+		Sampling:     RouteSamplingEven,
+	}
+	cases := []struct {
+		name  string
+		req   func(RouteRequest) RouteRequest
+		field string
+	}{
+		{"missing origin", func(r RouteRequest) RouteRequest { return r }, "from"},
+		{"two origins", func(r RouteRequest) RouteRequest {
+			r.From, r.FromPlaceID = "A", "place-a"
+			return r
+		}, "from"},
+		{"bad origin lat", func(r RouteRequest) RouteRequest {
+			r.FromLocation = &LatLng{Lat: 91}
+			return r
+		}, "from.lat"},
+		{"empty via", func(r RouteRequest) RouteRequest {
+			r.From = "A"
+			r.Via = []RouteLocation{{}}
+			return r
+		}, "via[0]"},
+		{"transit via", func(r RouteRequest) RouteRequest {
+			r.From, r.Mode = "A", travelModeTransit
+			r.Via = []RouteLocation{{Address: "C"}}
+			return r
+		}, "via"},
+		{"unknown strategy", func(r RouteRequest) RouteRequest {
+			r.From, r.Strategy = "A", "teleport"
+			return r
+		}, "strategy"},
+		{"native transit", func(r RouteRequest) RouteRequest {
+			r.From, r.Mode, r.Strategy = "A", travelModeTransit, RouteStrategyNative
+			return r
+		}, "strategy"},
+		{"walk modifiers", func(r RouteRequest) RouteRequest {
+			r.From, r.Mode = "A", travelModeWalk
+			r.Modifiers = &RouteModifiers{AvoidFerries: true}
+			return r
+		}, "modifiers"},
+		{"unknown sampling", func(r RouteRequest) RouteRequest {
+			r.From, r.Sampling = "A", "random"
+			return r
+		}, "sampling"},
+		{"even spacing", func(r RouteRequest) RouteRequest {
+			r.From, r.SpacingM = "A", 500
+			return r
+		}, "spacing_m"},
+		{"unknown sort", func(r RouteRequest) RouteRequest {
+			r.From, r.SortBy = "A", "rating"
+			return r
+		}, "sort_by"},
+		{"negative max detour", func(r RouteRequest) RouteRequest {
+			r.From, r.MaxDetourSeconds = "A", -1
+			return r
+		}, "max_detour_seconds"},
+	}
+	for _, tc := range cases {
+		err := validateRouteRequest(tc.req(base))
+		validation, ok := err.(ValidationError)
+		if !ok || validation.Field != tc.field {
+			t.Fatalf("%s: expected %s validation error, got %v", tc.name, tc.field, err)
+		}
+	}
+
+	base.FromLocation = &LatLng{Lat: 47.6, Lng: -122.3}
+	base.Via = []RouteLocation{{PlaceID: "place-stop"}}
+	base.Modifiers = &RouteModifiers{AvoidHighways: true}
+	if err := validateRouteRequest(base); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestValidateRouteRequestBounds(t *testing.T) {
 	err := validateRouteRequest(RouteRequest{
 		Query:        "coffee",
@@ -200,6 +400,18 @@ func TestApplyRouteDefaults(t *testing.T) {
 	if req.Limit != defaultRouteLimit {
 		t.Fatalf("unexpected limit: %d", req.Limit)
 	}
+	if req.Detours {
+		t.Fatalf("expected detours off by default")
+	}
+	if req := applyRouteDefaults(RouteRequest{RadiusM: 500, SpacingM: 0, Sampling: "Dense"}); req.SpacingM != 1000 || req.MaxWaypoints != defaultRouteSampleBudget {
+		t.Fatalf("unexpected dense sampling defaults: %#v", req)
+	}
+	if req := applyRouteDefaults(RouteRequest{SpacingM: 800}); req.Sampling != RouteSamplingDistance {
+		t.Fatalf("expected spacing to imply distance sampling: %#v", req)
+	}
+	if req := applyRouteDefaults(RouteRequest{SortBy: " Detour "}); req.SortBy != RouteSortDetour || !req.Detours {
+		t.Fatalf("expected detour sort to enable detours: %#v", req)
+	}
 }
 
 func TestApplyRouteDefaultsEmpty(t *testing.T) {
@@ -218,54 +430,181 @@ func TestApplyRouteDefaultsEmpty(t *testing.T) {
 	}
 }
 
-func TestComputeRoutePolylineErrors(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		_, _ = w.Write([]byte(`{"routes":[]}`))
+func TestComputeRouteDecodeErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"invalid JSON", "not-json", "decode route response"},
+		{"no routes", `{"routes":[]}`, "no routes returned"},
+		{"empty polyline", `{"routes":[{"polyline":{"encodedPolyline":" "}}]}`, "empty route polyline"},
+		{"malformed polyline", `{"routes":[{"polyline":{"encodedPolyline":"abc"}}]}`, "decode route polyline"},
+	}
+	for _, tc := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(tc.body))
+		}))
+		client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
+		_, err := client.computeRoute(context.Background(), RouteRequest{From: "A", To: "B"})
+		server.Close()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected %q error, got %v", tc.name, tc.want, err)
+		}
+		if tc.name == "malformed polyline" && !errors.Is(err, geo.ErrInvalidPolyline) {
+			t.Fatalf("expected wrapped invalid polyline error, got %v", err)
+		}
+	}
+}
+
+func TestRouteEndToEnd(t *testing.T) {
+	var searchCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesPath:
+			_, _ = w.Write([]byte("{\"routes\": [{\"polyline\": {\"encodedPolyline\": \"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case "/places:searchText":
+			searchCalls.Add(1)
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
 	}))
 	defer server.Close()
 
-	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
-	_, err := client.computeRoutePolyline(context.Background(), RouteRequest{From: "A", To: "B"})
-	if err == nil {
-		t.Fatalf("expected route error")
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL, RoutesBaseURL: server.URL})
+	response, err := client.Route(context.Background(), RouteRequest{
+		Query: "coffee",
+		From:  "Seattle",
+		To:    "Portland",
+	})
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if len(response.Waypoints) == 0 {
+		t.Fatalf("expected waypoints")
+	}
+	if response.Route == nil || response.Route.EncodedPolyline != "_p~iF~ps|U_ulLnnqC_mqNvxq`@" {
+		t.Fatalf("expected route metadata: %#v", response.Route)
+	}
+	if searchCalls.Load() == 0 {
+		t.Fatalf("expected search calls")
 	}
 }
 
-func TestComputeRoutePolylineEmpty(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		_, _ = w.Write([]byte(`{"routes":[{"polyline":{"encodedPolyline":""}}]}`))
+func TestRouteWithProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesPath:
+			_, _ = w.Write([]byte("{\"routes\": [{\"polyline\": {\"encodedPolyline\": \"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+		case "/places:searchText":
+			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
 	}))
 	defer server.Close()
 
-	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
-	_, err := client.computeRoutePolyline(context.Background(), RouteRequest{From: "A", To: "B"})
-	if err == nil {
-		t.Fatalf("expected empty polyline error")
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL, RoutesBaseURL: server.URL})
+	var events []string
+	seen := map[int]bool{}
+	response, err := client.RouteWithProgress(context.Background(), RouteRequest{
+		Query:        "coffee",
+		From:         "Seattle",
+		To:           "Portland",
+		MaxWaypoints: 3,
+		Concurrency:  3,
+	}, RouteProgress{
+		Route: func(route RouteInfo) {
+			events = append(events, "route")
+		},
+		Waypoint: func(index int, waypoint RouteWaypoint) {
+			events = append(events, "waypoint")
+			seen[index] = true
+			if len(waypoint.Results) != 1 {
+				t.Errorf("unexpected waypoint results: %#v", waypoint)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if len(events) != len(response.Waypoints)+1 || events[0] != "route" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	for i := range response.Waypoints {
+		if !seen[i] {
+			t.Fatalf("waypoint %d not reported", i)
+		}
 	}
 }
 
-func TestComputeRoutePolylineInvalidJSON(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		_, _ = w.Write([]byte("not-json"))
+func TestRouteDedupesPlaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesPath:
+			// Straight line due east along the equator: (0,0) -> (0,0.5) -> (0,1).
+			_, _ = w.Write([]byte(`{"routes": [{"polyline": {"encodedPolyline": "???_t` + "`" + `B?_t` + "`" + `B"}}]}`))
+		case "/places:searchText":
+			// Every waypoint sees the same place slightly north of the route midpoint.
+			_, _ = w.Write([]byte(`{"places":[{"id":"shared","location":{"latitude":0.01,"longitude":0.5}}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
 	}))
 	defer server.Close()
 
-	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
-	_, err := client.computeRoutePolyline(context.Background(), RouteRequest{From: "A", To: "B"})
-	if err == nil {
-		t.Fatalf("expected json error")
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL, RoutesBaseURL: server.URL})
+	response, err := client.Route(context.Background(), RouteRequest{
+		Query:        "coffee",
+		From:         "A",
+		To:           "B",
+		MaxWaypoints: 3,
+	})
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if len(response.Waypoints) != 3 {
+		t.Fatalf("expected 3 waypoints, got %d", len(response.Waypoints))
+	}
+	if len(response.Places) != 1 {
+		t.Fatalf("expected 1 deduplicated place, got %#v", response.Places)
+	}
+	place := response.Places[0]
+	if place.PlaceID != "shared" || place.WaypointIndex == nil || *place.WaypointIndex != 1 {
+		t.Fatalf("unexpected place: %#v", place)
+	}
+	half := geo.Distance(LatLng{Lat: 0, Lng: 0}, LatLng{Lat: 0, Lng: 0.5})
+	if place.DistanceAlongM == nil || math.Abs(*place.DistanceAlongM-half) > 1 {
+		t.Fatalf("unexpected distance along: %v", place.DistanceAlongM)
+	}
+	if place.DistanceFromRouteM == nil || math.Abs(*place.DistanceFromRouteM-1112) > 2 {
+		t.Fatalf("unexpected distance from route: %v", place.DistanceFromRouteM)
 	}
 }
 
-func TestRouteEndToEnd(t *testing.T) {
-	searchCalls := 0
+func TestRouteNativeStrategy(t *testing.T) {
+	var searchBody map[string]any
+	var searchMask string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case routesPath:
-			_, _ = w.Write([]byte("{\"routes\": [{\"polyline\": {\"encodedPolyline\": \"_p~iF~ps|U_ulLnnqC_mqNvxq`@\"}}]}"))
+			_, _ = w.Write([]byte(`{"routes": [{"polyline": {"encodedPolyline": "???_t` + "`" + `B?_t` + "`" + `B"}, "duration": "3600s", "distanceMeters": 111000}]}`))
 		case "/places:searchText":
-			searchCalls++
-			_, _ = w.Write([]byte(`{"places":[{"id":"abc","displayName":{"text":"Cafe"}}]}`))
+			searchMask = r.Header.Get("X-Goog-FieldMask")
+			if err := json.NewDecoder(r.Body).Decode(&searchBody); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{
+  "places": [
+    {"id": "far", "location": {"latitude": 0, "longitude": 0.8}},
+    {"id": "near", "location": {"latitude": 0.001, "longitude": 0.2}}
+  ],
+  "routingSummaries": [
+    {"legs": [{"duration": "3000s", "distanceMeters": 90000}, {"duration": "900s", "distanceMeters": 23000}]},
+    {"legs": [{"duration": "800s", "distanceMeters": 22000}]}
+  ]
+}`))
 		default:
 			t.Fatalf("unexpected path: %s", r.URL.Path)
 		}
@@ -274,18 +613,367 @@ func TestRouteEndToEnd(t *testing.T) {
 
 	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL, RoutesBaseURL: server.URL})
 	response, err := client.Route(context.Background(), RouteRequest{
-		Query: "coffee",
-		From:  "Seattle",
-		To:    "Portland",
+		Query:    "gas",
+		From:     "A",
+		To:       "B",
+		Strategy: RouteStrategyNative,
 	})
 	if err != nil {
 		t.Fatalf("route error: %v", err)
 	}
-	if len(response.Waypoints) == 0 {
-		t.Fatalf("expected waypoints")
+
+	if searchMask != searchFieldMask+","+searchRoutingSummaryMask {
+		t.Fatalf("unexpected search field mask: %s", searchMask)
 	}
-	if searchCalls == 0 {
-		t.Fatalf("expected search calls")
+	along := searchBody["searchAlongRouteParameters"].(map[string]any)["polyline"].(map[string]any)
+	if along["encodedPolyline"] != "???_t`B?_t`B" {
+		t.Fatalf("unexpected polyline: %#v", along)
+	}
+	routing := searchBody["routingParameters"].(map[string]any)
+	if routing["travelMode"] != travelModeDrive || routing["origin"] == nil {
+		t.Fatalf("unexpected routing parameters: %#v", routing)
+	}
+	if _, ok := searchBody["locationBias"]; ok {
+		t.Fatalf("unexpected location bias: %#v", searchBody["locationBias"])
+	}
+
+	if len(response.Waypoints) != 0 || len(response.Places) != 2 {
+		t.Fatalf("unexpected response: %#v", response)
+	}
+	far := response.Places[0]
+	if far.PlaceID != "far" || far.WaypointIndex != nil {
+		t.Fatalf("unexpected first place: %#v", far)
+	}
+	if far.DetourSeconds == nil || *far.DetourSeconds != 300 || far.DetourMeters == nil || *far.DetourMeters != 2000 {
+		t.Fatalf("unexpected detour: %v %v", far.DetourSeconds, far.DetourMeters)
+	}
+	if response.Places[1].DetourSeconds != nil {
+		t.Fatalf("expected no detour for single-leg summary")
+	}
+	if response.Places[1].DistanceFromRouteM == nil || *response.Places[1].DistanceFromRouteM > 200 {
+		t.Fatalf("unexpected distance from route: %v", response.Places[1].DistanceFromRouteM)
+	}
+}
+
+// matrixServer costs each pair as 10000 seconds and 100000 meters per degree
+// of latitude plus longitude. named maps addresses and place IDs to points;
+// each request's origin and destination counts are recorded.
+type matrixServer struct {
+	t        *testing.T
+	named    map[string]LatLng
+	mu       sync.Mutex
+	requests [][2]int
+}
+
+func (m *matrixServer) point(waypoint map[string]any) LatLng {
+	if location, ok := waypoint["location"].(map[string]any); ok {
+		latLng := location["latLng"].(map[string]any)
+		return LatLng{Lat: latLng["latitude"].(float64), Lng: latLng["longitude"].(float64)}
+	}
+	for _, key := range []string{"address", "placeId"} {
+		if name, ok := waypoint[key].(string); ok {
+			point, known := m.named[name]
+			if !known {
+				m.t.Errorf("unknown matrix waypoint %q", name)
+			}
+			return point
+		}
+	}
+	m.t.Errorf("unexpected matrix waypoint: %#v", waypoint)
+	return LatLng{}
+}
+
+func (m *matrixServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Goog-FieldMask") != routeMatrixFieldMask {
+		m.t.Errorf("unexpected matrix field mask: %s", r.Header.Get("X-Goog-FieldMask"))
+	}
+	var body struct {
+		Origins []struct {
+			Waypoint map[string]any `json:"waypoint"`
+		} `json:"origins"`
+		Destinations []struct {
+			Waypoint map[string]any `json:"waypoint"`
+		} `json:"destinations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		m.t.Errorf("decode matrix body: %v", err)
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, [2]int{len(body.Origins), len(body.Destinations)})
+	m.mu.Unlock()
+
+	named := 0
+	var elements []routeMatrixElement
+	for i, origin := range body.Origins {
+		if _, ok := origin.Waypoint["location"]; !ok {
+			named++
+		}
+		from := m.point(origin.Waypoint)
+		for j, destination := range body.Destinations {
+			if i == 0 {
+				if _, ok := destination.Waypoint["location"]; !ok {
+					named++
+				}
+			}
+			to := m.point(destination.Waypoint)
+			degrees := math.Abs(from.Lat-to.Lat) + math.Abs(from.Lng-to.Lng)
+			elements = append(elements, routeMatrixElement{
+				OriginIndex:      i,
+				DestinationIndex: j,
+				Duration:         fmt.Sprintf("%ds", int(math.Round(degrees*10000))),
+				DistanceMeters:   int(math.Round(degrees * 100000)),
+				Condition:        routeConditionExists,
+			})
+		}
+	}
+	if named > maxMatrixNamedWaypoints {
+		m.t.Errorf("matrix request has %d address or place ID waypoints", named)
+	}
+	_ = json.NewEncoder(w).Encode(elements)
+}
+
+func TestRouteNativeStrategyWithStops(t *testing.T) {
+	matrix := &matrixServer{t: t, named: map[string]LatLng{"A": {Lat: 0, Lng: 0}, "C": {Lat: 0, Lng: 0.5}, "B": {Lat: 0, Lng: 1}}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesPath:
+			_, _ = w.Write([]byte(`{"routes": [{"polyline": {"encodedPolyline": "???_t` + "`" + `B?_t` + "`" + `B"}, "duration": "3600s", "distanceMeters": 111000}]}`))
+		case "/places:searchText":
+			_, _ = w.Write([]byte(`{
+  "places": [{"id": "p", "location": {"latitude": 0.01, "longitude": 0.8}}],
+  "routingSummaries": [{"legs": [{"duration": "3000s", "distanceMeters": 90000}, {"duration": "900s", "distanceMeters": 23000}]}]
+}`))
+		case routeMatrixPath:
+			matrix.ServeHTTP(w, r)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL, RoutesBaseURL: server.URL})
+	request := RouteRequest{Query: "gas", From: "A", To: "B", Via: []RouteLocation{{Address: "C"}}, Strategy: RouteStrategyNative}
+	response, err := client.Route(context.Background(), request)
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if len(response.Places) != 1 || response.Places[0].DetourSeconds != nil {
+		t.Fatalf("expected no summary detour with a stop: %#v", response.Places)
+	}
+
+	// The matrix costs the detour on the C→B leg: 3100 + 2100 - 5000.
+	request.Detours = true
+	response, err = client.Route(context.Background(), request)
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if detour := response.Places[0].DetourSeconds; detour == nil || *detour != 200 {
+		t.Fatalf("unexpected detour: %v", detour)
+	}
+}
+
+func TestRouteMatrixDetours(t *testing.T) {
+	matrix := &matrixServer{t: t, named: map[string]LatLng{"A": {Lat: 0, Lng: 0}, "B": {Lat: 0, Lng: 1}}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case routesPath:
+			_, _ = w.Write([]byte(`{"routes": [{"polyline": {"encodedPolyline": "???_t` + "`" + `B?_t` + "`" + `B"}}]}`))
+		case "/places:searchText":
+			_, _ = w.Write([]byte(`{"places":[
+  {"id":"a","location":{"latitude":0.01,"longitude":0.2}},
+  {"id":"b","location":{"latitude":0.005,"longitude":0.8}}
+]}`))
+		case routeMatrixPath:
+			matrix.ServeHTTP(w, r)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL, RoutesBaseURL: server.URL})
+	request := RouteRequest{Query: "coffee", From: "A", To: "B", MaxWaypoints: 1, SortBy: RouteSortDetour}
+	response, err := client.Route(context.Background(), request)
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if len(matrix.requests) != 2 {
+		t.Fatalf("expected 2 matrix calls, got %v", matrix.requests)
+	}
+	if len(response.Places) != 2 || response.Places[0].PlaceID != "b" || response.Places[1].PlaceID != "a" {
+		t.Fatalf("expected places sorted by detour: %#v", response.Places)
+	}
+	b, a := response.Places[0], response.Places[1]
+	if b.DetourSeconds == nil || *b.DetourSeconds != 100 || b.DetourMeters == nil || *b.DetourMeters != 1000 {
+		t.Fatalf("unexpected detour for b: %v %v", b.DetourSeconds, b.DetourMeters)
+	}
+	if a.DetourSeconds == nil || *a.DetourSeconds != 200 || a.DetourMeters == nil || *a.DetourMeters != 2000 {
+		t.Fatalf("unexpected detour for a: %v %v", a.DetourSeconds, a.DetourMeters)
+	}
+
+	request.SortBy = ""
+	request.MaxDetourSeconds = 150
+	response, err = client.Route(context.Background(), request)
+	if err != nil {
+		t.Fatalf("route error: %v", err)
+	}
+	if len(response.Places) != 1 || response.Places[0].PlaceID != "b" {
+		t.Fatalf("expected only b within max detour: %#v", response.Places)
+	}
+}
+
+func TestAddDetoursUsesIntermediates(t *testing.T) {
+	matrix := &matrixServer{t: t, named: map[string]LatLng{"A": {Lat: 0, Lng: 0}, "C": {Lat: 0.5, Lng: 0.5}, "B": {Lat: 0, Lng: 1}}}
+	server := httptest.NewServer(matrix)
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
+	request := applyRouteDefaults(RouteRequest{From: "A", To: "B", Via: []RouteLocation{{Address: "C"}}})
+	places := []RoutePlace{{PlaceSummary: PlaceSummary{PlaceID: "p", Location: &LatLng{Lat: 0.52, Lng: 0.55}}}}
+	if err := client.addDetours(context.Background(), request, places); err != nil {
+		t.Fatalf("detours error: %v", err)
+	}
+	// Cheapest between C and B: 700 + 9700 - 10000. Without the stop at C it
+	// would be 10700 + 9700 - 10000.
+	if places[0].DetourSeconds == nil || *places[0].DetourSeconds != 400 {
+		t.Fatalf("unexpected detour: %v", places[0].DetourSeconds)
+	}
+}
+
+func TestAddDetoursBatchesMatrix(t *testing.T) {
+	cases := []struct {
+		name        string
+		mode        string
+		byPlaceID   bool
+		places      int
+		maxElements int
+	}{
+		{name: "transit elements", mode: travelModeTransit, places: 150, maxElements: maxTransitMatrixElements},
+		{name: "drive elements", mode: travelModeDrive, places: 700, maxElements: maxMatrixElements},
+		{name: "place IDs", mode: travelModeDrive, byPlaceID: true, places: 60, maxElements: maxMatrixElements},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			matrix := &matrixServer{t: t, named: map[string]LatLng{"A": {Lat: 0, Lng: 0}, "B": {Lat: 0, Lng: 1}}}
+			server := httptest.NewServer(matrix)
+			defer server.Close()
+
+			places := make([]RoutePlace, tc.places)
+			for i := range places {
+				location := LatLng{Lat: 0.001, Lng: float64(i) / float64(tc.places)}
+				places[i].PlaceID = fmt.Sprintf("p%d", i)
+				if tc.byPlaceID {
+					matrix.named[places[i].PlaceID] = location
+				} else {
+					places[i].Location = &location
+				}
+			}
+			client := NewClient(Options{APIKey: "test-key", RoutesBaseURL: server.URL})
+			request := applyRouteDefaults(RouteRequest{From: "A", To: "B", Mode: tc.mode})
+			if err := client.addDetours(context.Background(), request, places); err != nil {
+				t.Fatalf("detours error: %v", err)
+			}
+			if len(matrix.requests) < 4 {
+				t.Fatalf("expected batched matrix calls, got %v", matrix.requests)
+			}
+			for _, counts := range matrix.requests {
+				if counts[0]*counts[1] > tc.maxElements {
+					t.Fatalf("matrix request has %d elements", counts[0]*counts[1])
+				}
+			}
+			for i, place := range places {
+				if place.DetourSeconds == nil || *place.DetourSeconds != 20 {
+					t.Fatalf("unexpected detour for place %d: %v", i, place.DetourSeconds)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderRoutePlaces(t *testing.T) {
+	along := func(v float64) *float64 { return &v }
+	detour := func(v int) *int { return &v }
+	places := []RoutePlace{
+		{PlaceSummary: PlaceSummary{PlaceID: "unknown"}, DistanceAlongM: along(50)},
+		{PlaceSummary: PlaceSummary{PlaceID: "slow"}, DistanceAlongM: along(300), DetourSeconds: detour(900)},
+		{PlaceSummary: PlaceSummary{PlaceID: "fast"}, DistanceAlongM: along(100), DetourSeconds: detour(60)},
+	}
+
+	ids := func(places []RoutePlace) string {
+		out := ""
+		for _, place := range places {
+			out += place.PlaceID + " "
+		}
+		return out
+	}
+	sorted := orderRoutePlaces(RouteRequest{SortBy: RouteSortDetour}, append([]RoutePlace(nil), places...))
+	if got := ids(sorted); got != "fast slow unknown " {
+		t.Fatalf("unexpected detour order: %s", got)
+	}
+	sorted = orderRoutePlaces(RouteRequest{SortBy: RouteSortRoute}, append([]RoutePlace(nil), places...))
+	if got := ids(sorted); got != "unknown fast slow " {
+		t.Fatalf("unexpected route order: %s", got)
+	}
+	filtered := orderRoutePlaces(RouteRequest{MaxDetourSeconds: 600}, append([]RoutePlace(nil), places...))
+	if got := ids(filtered); got != "fast " {
+		t.Fatalf("unexpected filtered places: %s", got)
+	}
+}
+
+func TestSearchWaypointsBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"places":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{APIKey: "test-key", BaseURL: server.URL})
+	waypoints := make([]LatLng, 8)
+	for i := range waypoints {
+		waypoints[i] = LatLng{Lat: float64(i), Lng: 0}
+	}
+	results, err := client.searchWaypoints(context.Background(), RouteRequest{
+		Query:       "coffee",
+		Limit:       1,
+		RadiusM:     100,
+		Concurrency: 2,
+	}, waypoints, nil)
+	if err != nil {
+		t.Fatalf("searchWaypoints error: %v", err)
+	}
+	if peak.Load() > 2 {
+		t.Fatalf("expected at most 2 concurrent searches, got %d", peak.Load())
+	}
+	for i, result := range results {
+		if result.Location != waypoints[i] {
+			t.Fatalf("waypoint %d out of order: %#v", i, result.Location)
+		}
+	}
+}
+
+func TestDedupeRoutePlacesWithoutLocation(t *testing.T) {
+	points := []LatLng{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}
+	places := dedupeRoutePlaces(points, []RouteWaypoint{
+		{Location: points[0], Results: []PlaceSummary{{PlaceID: "no-location"}, {PlaceID: "far", Location: &LatLng{Lat: 0, Lng: 0.9}}}},
+		{Location: points[1], Results: []PlaceSummary{{PlaceID: "near", Location: &LatLng{Lat: 0, Lng: 0.1}}, {PlaceID: "far"}}},
+	})
+	if len(places) != 3 {
+		t.Fatalf("expected 3 places, got %#v", places)
+	}
+	if places[0].PlaceID != "near" || places[1].PlaceID != "far" || places[2].PlaceID != "no-location" {
+		t.Fatalf("unexpected order: %#v", places)
+	}
+	if *places[1].WaypointIndex != 1 || *places[2].WaypointIndex != 0 || places[2].DistanceAlongM != nil {
+		t.Fatalf("unexpected annotations: %#v", places)
 	}
 }
 
@@ -8,7 +8,10 @@ import (
 	"strings"
 )
 
-const searchFieldMask = "places.id,places.displayName,places.formattedAddress,places.location,places.rating,places.userRatingCount,places.priceLevel,places.types,places.currentOpeningHours,nextPageToken"
+const (
+	searchFieldMask          = "places.id,places.displayName,places.formattedAddress,places.location,places.rating,places.userRatingCount,places.priceLevel,places.types,places.currentOpeningHours,nextPageToken"
+	searchRoutingSummaryMask = "routingSummaries"
+)
 
 // Search performs a text search with optional filters.
 func (c *Client) Search(ctx context.Context, req SearchRequest) (SearchResponse, error) {
@@ -22,7 +25,7 @@ func (c *Client) Search(ctx context.Context, req SearchRequest) (SearchResponse,
 	if err != nil {
 		return SearchResponse{}, err
 	}
-	payload, err := c.doRequest(ctx, http.MethodPost, endpoint, body, searchFieldMask)
+	payload, err := c.doRequest(ctx, http.MethodPost, endpoint, body, searchFieldMaskForRequest(req))
 	if err != nil {
 		return SearchResponse{}, err
 	}
@@ -38,11 +41,20 @@ func (c *Client) Search(ctx context.Context, req SearchRequest) (SearchResponse,
 	}
 
 	return SearchResponse{
-		Results:       results,
-		NextPageToken: response.NextPageToken,
+		Results:          results,
+		NextPageToken:    response.NextPageToken,
+		RoutingSummaries: mapRoutingSummaries(response.RoutingSummaries),
 	}, nil
 }
 
+func searchFieldMaskForRequest(req SearchRequest) string {
+	if req.AlongRoute != nil && req.AlongRoute.Origin != nil {
+		// Routing summaries are only returned when an origin is given.
+		return searchFieldMask + "," + searchRoutingSummaryMask
+	}
+	return searchFieldMask
+}
+
 func buildSearchBody(req SearchRequest) map[string]any {
 	textQuery := req.Query
 	if req.Filters != nil && strings.TrimSpace(req.Filters.Keyword) != "" {
@@ -70,6 +82,26 @@ func buildSearchBody(req SearchRequest) map[string]any {
 		body["locationBias"] = circlePayload(req.LocationBias)
 	}
 
+	if req.AlongRoute != nil {
+		body["searchAlongRouteParameters"] = map[string]any{
+			"polyline": map[string]any{
+				"encodedPolyline": req.AlongRoute.EncodedPolyline,
+			},
+		}
+		if req.AlongRoute.Origin != nil {
+			routing := map[string]any{
+				"origin": map[string]any{
+					"latitude":  req.AlongRoute.Origin.Lat,
+					"longitude": req.AlongRoute.Origin.Lng,
+				},
+			}
+			if req.AlongRoute.TravelMode != "" {
+				routing["travelMode"] = req.AlongRoute.TravelMode
+			}
+			body["routingParameters"] = routing
+		}
+	}
+
 	if req.Filters != nil {
 		filters := req.Filters
 		if len(filters.Types) > 0 {
@@ -138,6 +170,9 @@ func validateSearchRequest(req SearchRequest) error {
 				return ValidationError{Field: "filters.price_levels", Message: "must be 0-4"}
 			}
 		}
+		if err := validatePlaceTypes("filters.types", req.Filters.Types); err != nil {
+			return err
+		}
 	}
 
 	if req.LocationBias != nil {
@@ -146,5 +181,9 @@ func validateSearchRequest(req SearchRequest) error {
 		}
 	}
 
+	if req.AlongRoute != nil && strings.TrimSpace(req.AlongRoute.EncodedPolyline) == "" {
+		return ValidationError{Field: "along_route.encoded_polyline", Message: "required"}
+	}
+
 	return nil
 }
@@ -1,5 +1,7 @@
 package gplace
 
+import "github.com/qztseng/gplace/geo"
+
 // SearchRequest defines a text search with optional filters.
 type SearchRequest struct {
 	Query        string        `json:"query"`
@@ -9,6 +11,16 @@ type SearchRequest struct {
 	PageToken    string        `json:"page_token,omitempty"`
 	Language     string        `json:"language,omitempty"`
 	Region       string        `json:"region,omitempty"`
+	// AlongRoute ranks results along a route instead of around a point.
+	AlongRoute *SearchAlongRoute `json:"along_route,omitempty"`
+}
+
+// SearchAlongRoute restricts a text search to places along an encoded route
+// polyline. When Origin is set, results include routing summaries.
+type SearchAlongRoute struct {
+	EncodedPolyline string  `json:"encoded_polyline"`
+	Origin          *LatLng `json:"origin,omitempty"`
+	TravelMode      string  `json:"travel_mode,omitempty"`
 }
 
 // Filters are optional search refinements.
@@ -27,16 +39,30 @@ type LocationBias struct {
 	RadiusM float64 `json:"radius_m"`
 }
 
-// LatLng holds geographic coordinates.
-type LatLng struct {
-	Lat float64 `json:"lat"`
-	Lng float64 `json:"lng"`
-}
+// LatLng holds geographic coordinates. It is the geo package type, so route
+// polylines can be passed to the geo helpers directly.
+type LatLng = geo.LatLng
 
 // SearchResponse contains a list of places and optional pagination token.
 type SearchResponse struct {
 	Results       []PlaceSummary `json:"results"`
 	NextPageToken string         `json:"next_page_token,omitempty"`
+	// RoutingSummaries parallels Results for searches with routing parameters.
+	RoutingSummaries []RoutingSummary `json:"routing_summaries,omitempty"`
+}
+
+// RoutingSummary describes travel from the routing origin to a result. For
+// searches along a route the legs are origin to place and place to the end
+// of the route.
+type RoutingSummary struct {
+	Legs          []RoutingLeg `json:"legs,omitempty"`
+	DirectionsURI string       `json:"directions_uri,omitempty"`
+}
+
+// RoutingLeg is one leg of a routing summary.
+type RoutingLeg struct {
+	DurationSeconds int `json:"duration_seconds"`
+	DistanceMeters  int `json:"distance_meters"`
 }
 
 // AutocompleteRequest defines input for autocomplete suggestions.
@@ -56,14 +82,24 @@ type AutocompleteResponse struct {
 
 // AutocompleteSuggestion is a place or query prediction.
 type AutocompleteSuggestion struct {
-	Kind           string   `json:"kind"`
-	PlaceID        string   `json:"place_id,omitempty"`
-	Place          string   `json:"place,omitempty"`
-	Text           string   `json:"text,omitempty"`
-	MainText       string   `json:"main_text,omitempty"`
-	SecondaryText  string   `json:"secondary_text,omitempty"`
-	Types          []string `json:"types,omitempty"`
-	DistanceMeters *int     `json:"distance_meters,omitempty"`
+	Kind                 string      `json:"kind"`
+	PlaceID              string      `json:"place_id,omitempty"`
+	Place                string      `json:"place,omitempty"`
+	Text                 string      `json:"text,omitempty"`
+	TextMatches          []TextMatch `json:"text_matches,omitempty"`
+	MainText             string      `json:"main_text,omitempty"`
+	MainTextMatches      []TextMatch `json:"main_text_matches,omitempty"`
+	SecondaryText        string      `json:"secondary_text,omitempty"`
+	SecondaryTextMatches []TextMatch `json:"secondary_text_matches,omitempty"`
+	Types                []string    `json:"types,omitempty"`
+	DistanceMeters       *int        `json:"distance_meters,omitempty"`
+}
+
+// TextMatch marks the part of a suggestion text that matched the input.
+// Offsets count Unicode characters; EndOffset is exclusive.
+type TextMatch struct {
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
 }
 
 // NearbySearchRequest defines a nearby search query.
@@ -167,6 +203,8 @@ type DetailsRequest struct {
 	Region   string `json:"region,omitempty"`
 	// IncludeReviews requests the reviews field in Place Details.
 	IncludeReviews bool `json:"include_reviews,omitempty"`
+	// SessionToken closes an autocomplete session started with the same token.
+	SessionToken string `json:"session_token,omitempty"`
 }
 
 // Review represents a user review of a place.